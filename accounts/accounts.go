@@ -0,0 +1,112 @@
+// Package accounts stores player accounts that outlive any one game.
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-api/utils"
+)
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 32
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+	MaxDisplayName    = 64
+)
+
+var (
+	ErrNotFound      = errors.New("account not found")
+	ErrUsernameTaken = errors.New("username is already taken")
+)
+
+// Account is a player identity shared across games. PasswordHash never
+// leaves the server.
+type Account struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	DisplayName  string    `json:"display_name"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Store persists accounts. Usernames are unique regardless of case.
+// Implementations must be safe for concurrent use and must return copies,
+// so callers can modify what they get back and pass it to Update.
+type Store interface {
+	Create(a *Account) error
+	Get(id string) (*Account, error)
+	GetByUsername(username string) (*Account, error)
+	Update(a *Account) error
+}
+
+// New validates a registration and builds the account, hashing its
+// password. It does not store it.
+func New(username, password, displayName string) (*Account, error) {
+	username = strings.TrimSpace(username)
+	if err := ValidateUsername(username); err != nil {
+		return nil, err
+	}
+	if err := ValidatePassword(password); err != nil {
+		return nil, err
+	}
+
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		displayName = username
+	}
+	if err := ValidateDisplayName(displayName); err != nil {
+		return nil, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		ID:           utils.GenerateID(12),
+		Username:     username,
+		DisplayName:  displayName,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// ValidateUsername allows letters, digits, '_' and '-'.
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return fmt.Errorf("username must be %d-%d characters", MinUsernameLength, MaxUsernameLength)
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return fmt.Errorf("username may only contain letters, digits, '_' and '-'")
+		}
+	}
+	return nil
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be %d-%d characters", MinPasswordLength, MaxPasswordLength)
+	}
+	return nil
+}
+
+func ValidateDisplayName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > MaxDisplayName {
+		return fmt.Errorf("display name must be 1-%d characters", MaxDisplayName)
+	}
+	return nil
+}
+
+// usernameKey is how usernames are compared for uniqueness.
+func usernameKey(username string) string {
+	return strings.ToLower(username)
+}
@@ -0,0 +1,72 @@
+package accounts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		username    string
+		password    string
+		displayName string
+		want        string
+	}{
+		{"short username", "ab", "password1", "", "username must be 3-32 characters"},
+		{"long username", strings.Repeat("a", MaxUsernameLength+1), "password1", "", "username must be 3-32 characters"},
+		{"username with spaces", "a b c", "password1", "", "username may only contain letters, digits, '_' and '-'"},
+		{"username with symbols", "abc!", "password1", "", "username may only contain letters, digits, '_' and '-'"},
+		{"short password", "alice", "short", "", "password must be 8-128 characters"},
+		{"long password", "alice", strings.Repeat("p", MaxPasswordLength+1), "", "password must be 8-128 characters"},
+		{"long display name", "alice", "password1", strings.Repeat("é", MaxDisplayName+1), "display name must be 1-64 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.username, tt.password, tt.displayName)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	a, err := New("  alice_1 ", "password1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Username != "alice_1" {
+		t.Errorf("Username = %q, want alice_1", a.Username)
+	}
+	if a.DisplayName != "alice_1" {
+		t.Errorf("DisplayName = %q, want the username", a.DisplayName)
+	}
+	if a.ID == "" || a.CreatedAt.IsZero() {
+		t.Errorf("account = %+v, want an ID and creation time", a)
+	}
+	if !CheckPassword(a.PasswordHash, "password1") {
+		t.Error("password does not match its hash")
+	}
+}
+
+func TestValidateDisplayName(t *testing.T) {
+	tests := []struct {
+		name    string
+		display string
+		ok      bool
+	}{
+		{"empty", "", false},
+		{"longest", strings.Repeat("é", MaxDisplayName), true},
+		{"too long", strings.Repeat("a", MaxDisplayName+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDisplayName(tt.display); (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
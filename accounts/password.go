@@ -0,0 +1,84 @@
+package accounts
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 600000
+	saltLength     = 16
+	keyLength      = 32
+)
+
+// HashPassword derives a salted PBKDF2-SHA256 hash, encoded as
+// "pbkdf2-sha256$<iterations>$<salt>$<key>" so the cost can be raised
+// later without invalidating stored hashes.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, hashIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches hash. Malformed hashes
+// never match.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// dummyHash is checked against when a username does not exist, so a failed
+// login takes as long whether or not the account is real.
+var dummyHash, _ = HashPassword("not a real password")
+
+// Authenticate looks up an account and checks its password. Unknown
+// usernames and wrong passwords both return ErrNotFound.
+func Authenticate(store Store, username, password string) (*Account, error) {
+	a, err := store.GetByUsername(username)
+	if err != nil {
+		CheckPassword(dummyHash, password)
+		return nil, ErrNotFound
+	}
+	if !CheckPassword(a.PasswordHash, password) {
+		return nil, ErrNotFound
+	}
+	return a, nil
+}
@@ -0,0 +1,100 @@
+package accounts
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// cheapHash hashes password with few iterations, so tests stay fast.
+func cheapHash(t *testing.T, password string) string {
+	t.Helper()
+	salt := []byte("0123456789abcdef")
+	key, err := pbkdf2.Key(sha256.New, password, salt, 10, keyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, 10, enc.EncodeToString(salt), enc.EncodeToString(key))
+}
+
+func TestHashPassword(t *testing.T) {
+	a, err := HashPassword("password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := HashPassword("password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("two hashes of one password are equal; salts must differ")
+	}
+	if !strings.HasPrefix(a, fmt.Sprintf("%s$%d$", hashScheme, hashIterations)) {
+		t.Errorf("hash %q does not record its scheme and cost", a)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash := cheapHash(t, "password1")
+	parts := strings.Split(hash, "$")
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"right password", hash, "password1", true},
+		{"wrong password", hash, "password2", false},
+		{"empty hash", "", "password1", false},
+		{"unknown scheme", "bcrypt$" + strings.Join(parts[1:], "$"), "password1", false},
+		{"missing part", strings.Join(parts[:3], "$"), "password1", false},
+		{"bad iterations", parts[0] + "$x$" + parts[2] + "$" + parts[3], "password1", false},
+		{"zero iterations", parts[0] + "$0$" + parts[2] + "$" + parts[3], "password1", false},
+		{"bad salt", parts[0] + "$" + parts[1] + "$!!$" + parts[3], "password1", false},
+		{"bad key", parts[0] + "$" + parts[1] + "$" + parts[2] + "$!!", "password1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("CheckPassword = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Create(&Account{ID: "1", Username: "Alice", PasswordHash: cheapHash(t, "password1")}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{"right password", "Alice", "password1", nil},
+		{"username in another case", "alice", "password1", nil},
+		{"wrong password", "Alice", "password2", ErrNotFound},
+		{"unknown username", "bob", "password1", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Authenticate(store, tt.username, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && a.ID != "1" {
+				t.Errorf("account = %+v, want ID 1", a)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps accounts in memory only. It is the default when no
+// accounts file is configured.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	byID       map[string]*Account
+	byUsername map[string]*Account
+
+	// onChange runs after every successful write, with mu still held
+	onChange func() error
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		byID:       make(map[string]*Account),
+		byUsername: make(map[string]*Account),
+	}
+}
+
+func (s *MemoryStore) Create(a *Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := usernameKey(a.Username)
+	if s.byUsername[key] != nil {
+		return ErrUsernameTaken
+	}
+	if s.byID[a.ID] != nil {
+		return fmt.Errorf("account %s already exists", a.ID)
+	}
+
+	stored := *a
+	s.byID[a.ID] = &stored
+	s.byUsername[key] = &stored
+	if err := s.changed(); err != nil {
+		delete(s.byID, a.ID)
+		delete(s.byUsername, key)
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (*Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := s.byID[id]
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (s *MemoryStore) GetByUsername(username string) (*Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := s.byUsername[usernameKey(username)]
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// Update replaces an account's stored fields. Usernames cannot change.
+func (s *MemoryStore) Update(a *Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.byID[a.ID]
+	if stored == nil {
+		return ErrNotFound
+	}
+	if usernameKey(a.Username) != usernameKey(stored.Username) {
+		return fmt.Errorf("username cannot be changed")
+	}
+
+	previous := *stored
+	*stored = *a
+	if err := s.changed(); err != nil {
+		*stored = previous
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStore) changed() error {
+	if s.onChange == nil {
+		return nil
+	}
+	return s.onChange()
+}
+
+// FileStore is a MemoryStore that rewrites a JSON file after every change.
+// Writes go to a temporary file first, so a crash never leaves the file
+// half-written.
+type FileStore struct {
+	*MemoryStore
+	path string
+}
+
+// fileRecord is an account as written to disk, including its hash.
+type fileRecord struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	DisplayName  string    `json:"display_name"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewFileStore loads the accounts in path, which is created on the first
+// write if it does not exist.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var records []fileRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, rec := range records {
+			a := Account(rec)
+			key := usernameKey(a.Username)
+			if s.byUsername[key] != nil {
+				return nil, fmt.Errorf("parsing %s: duplicate username %q", path, a.Username)
+			}
+			s.byID[a.ID] = &a
+			s.byUsername[key] = &a
+		}
+	}
+
+	s.onChange = s.save
+	return s, nil
+}
+
+// Len returns how many accounts are stored.
+func (s *FileStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byID)
+}
+
+// save writes every account to disk. The caller must hold s.mu.
+func (s *FileStore) save() error {
+	records := make([]fileRecord, 0, len(s.byID))
+	for _, a := range s.byID {
+		records = append(records, fileRecord(*a))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
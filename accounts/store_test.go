@@ -0,0 +1,104 @@
+package accounts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	if err := s.Create(&Account{ID: "1", Username: "Alice", DisplayName: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"duplicate username", s.Create(&Account{ID: "2", Username: "alice"}), ErrUsernameTaken.Error()},
+		{"duplicate ID", s.Create(&Account{ID: "1", Username: "bob"}), "account 1 already exists"},
+		{"update unknown account", s.Update(&Account{ID: "3", Username: "carol"}), ErrNotFound.Error()},
+		{"rename", s.Update(&Account{ID: "1", Username: "alicia"}), "username cannot be changed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil || tt.err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", tt.err, tt.want)
+			}
+		})
+	}
+
+	// Callers get copies, so changes only stick through Update
+	a, err := s.GetByUsername("ALICE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.DisplayName = "Changed"
+	if got, _ := s.Get("1"); got.DisplayName != "Alice" {
+		t.Errorf("DisplayName = %q without Update, want Alice", got.DisplayName)
+	}
+	if err := s.Update(a); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get("1"); got.DisplayName != "Changed" {
+		t.Errorf("DisplayName = %q after Update, want Changed", got.DisplayName)
+	}
+	if _, err := s.Get("nope"); err != ErrNotFound {
+		t.Errorf("Get(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(&Account{ID: "1", Username: "alice", PasswordHash: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := reloaded.GetByUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.PasswordHash != "hash" {
+		t.Errorf("PasswordHash = %q after reload, want hash", a.PasswordHash)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestNewFileStoreErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", "accounts"},
+		{"duplicate username", `[{"id":"1","username":"alice"},{"id":"2","username":"ALICE"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "accounts.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewFileStore(path); err == nil {
+				t.Error("NewFileStore accepted a bad file")
+			}
+		})
+	}
+}
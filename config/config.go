@@ -5,19 +5,65 @@ import (
 	"encoding/hex"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port           string
 	JWTSecret      []byte
 	AllowedOrigins string
+	ChatFilterFile string
+	QuestsFile     string
+	AccountsFile   string // accounts are kept in memory only when empty
+
+	// AdminCredential is exchanged at /admin/login for an admin token.
+	// Admin access is off when it is empty.
+	AdminCredential string
+
+	// JWTAlgorithm is HS256 (signed with JWTSecret), EdDSA or RS256 (signed
+	// with the PEM key in JWTPrivateKeyFile, or a generated one).
+	JWTAlgorithm      string
+	JWTPrivateKeyFile string
+	// JWTPreviousSecrets still verify HS256 tokens but never sign, so a
+	// secret can be replaced without logging anyone out.
+	JWTPreviousSecrets [][]byte
+	// JWTKeyRotation generates a new signing key this often; 0 never does.
+	JWTKeyRotation time.Duration
 }
 
 func Load() *Config {
 	cfg := &Config{
 		Port:           getEnv("PORT", "8080"),
 		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "*"),
+		ChatFilterFile: os.Getenv("CHAT_FILTER_FILE"),
+		QuestsFile:     os.Getenv("QUESTS_FILE"),
+		AccountsFile:   os.Getenv("ACCOUNTS_FILE"),
+
+		AdminCredential: os.Getenv("ADMIN_CREDENTIAL"),
+
+		JWTAlgorithm:      getEnv("JWT_ALGORITHM", "HS256"),
+		JWTPrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
 	}
+
+	if v := os.Getenv("JWT_KEY_ROTATION"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval < 0 {
+			log.Fatal("Invalid JWT_KEY_ROTATION. Must be a duration such as 24h.")
+		}
+		cfg.JWTKeyRotation = interval
+	}
+
+	if v := os.Getenv("JWT_PREVIOUS_SECRETS"); v != "" {
+		for _, secretHex := range strings.Split(v, ",") {
+			secret, err := hex.DecodeString(strings.TrimSpace(secretHex))
+			if err != nil {
+				log.Fatal("Invalid JWT_PREVIOUS_SECRETS format. Must be comma-separated hex.")
+			}
+			cfg.JWTPreviousSecrets = append(cfg.JWTPreviousSecrets, secret)
+		}
+	}
+
 	jwtSecretHex := os.Getenv("JWT_SECRET")
 	if jwtSecretHex != "" {
 		secret, err := hex.DecodeString(jwtSecretHex)
@@ -26,7 +72,7 @@ func Load() *Config {
 		}
 		cfg.JWTSecret = secret
 		log.Println("Loaded JWT secret from environment")
-	} else {
+	} else if cfg.JWTAlgorithm == "HS256" {
 		cfg.JWTSecret = generateSecret()
 		log.Printf("WARNING: No JWT_SECRET set. Generated temporary secret: %s", hex.EncodeToString(cfg.JWTSecret))
 		log.Println("Set JWT_SECRET environment variable for production!")
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+)
+
+// DefaultLocationCount is the size of a world when none is requested, and
+// MaxLocationCount the largest allowed.
+const (
+	DefaultLocationCount = 10
+	MaxLocationCount     = 100
+)
+
+// Visibility controls whether a game is advertised. Unlisted games can
+// still be joined by anyone who knows the ID; private games also require an
+// invite.
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilityUnlisted Visibility = "unlisted"
+	VisibilityPrivate  Visibility = "private"
+)
+
+// Listed reports whether the game appears in game listings.
+func (v Visibility) Listed() bool {
+	return v == VisibilityPublic
+}
+
+// JoinPolicy is what a player must present to join.
+type JoinPolicy string
+
+const (
+	JoinOpen     JoinPolicy = "open"
+	JoinPassword JoinPolicy = "password"
+	JoinInvite   JoinPolicy = "invite"
+)
+
+var (
+	ErrGameFull      = errors.New("game is full")
+	ErrAccountJoined = errors.New("account already has a character in this game")
+)
+
+// JoinError is returned when a player may not join because of the code
+// they presented.
+type JoinError struct {
+	Reason string
+}
+
+func (e *JoinError) Error() string { return e.Reason }
+
+var (
+	ErrJoinCodeNeeded = &JoinError{"a password or invite code is required to join"}
+	ErrJoinCodeWrong  = &JoinError{"incorrect password or invite code"}
+	ErrInviteExpired  = &JoinError{"invite has expired"}
+	ErrInviteRevoked  = &JoinError{"invite has been revoked"}
+	ErrInviteUsedUp   = &JoinError{"invite has no uses left"}
+	ErrBanned         = &JoinError{"you are banned from this game"}
+)
+
+// admit checks the password or invite code a joining player presented and,
+// for invites, records the use. Open games accept anything. The caller must
+// hold g.Mu.
+func (g *Game) admit(playerID, code string, now time.Time) error {
+	switch g.Join {
+	case JoinPassword:
+		if code == "" {
+			return ErrJoinCodeNeeded
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(g.joinSecret)) != 1 {
+			return ErrJoinCodeWrong
+		}
+	case JoinInvite:
+		if code == "" {
+			return ErrJoinCodeNeeded
+		}
+		inv := g.invites[code]
+		if inv == nil {
+			return ErrJoinCodeWrong
+		}
+		if err := inv.check(now); err != nil {
+			return err
+		}
+		inv.Uses++
+		inv.UsedBy = append(inv.UsedBy, playerID)
+	}
+	return nil
+}
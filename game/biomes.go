@@ -0,0 +1,262 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+type Biome string
+
+const (
+	BiomeForest    Biome = "forest"
+	BiomeMountains Biome = "mountains"
+	BiomeDesert    Biome = "desert"
+	BiomeTundra    Biome = "tundra"
+	BiomeSwamp     Biome = "swamp"
+	BiomeCoast     Biome = "coast"
+	BiomeCaverns   Biome = "caverns"
+)
+
+var Biomes = []Biome{BiomeForest, BiomeMountains, BiomeDesert, BiomeTundra, BiomeSwamp, BiomeCoast, BiomeCaverns}
+
+func (b Biome) valid() bool {
+	for _, known := range Biomes {
+		if b == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NamePool supplies the words location names are built from. Names are an
+// adjective followed by a noun, e.g. "Whispering Grove".
+type NamePool interface {
+	Adjectives(biome Biome) []string
+	Nouns(biome Biome) []string
+}
+
+// BiomeNames is a NamePool backed by fixed word lists.
+type BiomeNames map[Biome]struct {
+	Adjectives []string
+	Nouns      []string
+}
+
+func (b BiomeNames) Adjectives(biome Biome) []string { return b[biome].Adjectives }
+func (b BiomeNames) Nouns(biome Biome) []string      { return b[biome].Nouns }
+
+// DefaultNames is the name pool used when none is given.
+var DefaultNames = BiomeNames{
+	BiomeForest: {
+		Adjectives: []string{"Whispering", "Tangled", "Shadowed", "Ancient", "Mossy", "Silent", "Verdant", "Hollow"},
+		Nouns:      []string{"Grove", "Thicket", "Glade", "Wood", "Clearing", "Copse"},
+	},
+	BiomeMountains: {
+		Adjectives: []string{"Windswept", "Jagged", "Lonely", "Frostbitten", "Towering", "Broken", "Grey", "Iron"},
+		Nouns:      []string{"Peak", "Pass", "Ridge", "Crag", "Summit", "Cliffs"},
+	},
+	BiomeDesert: {
+		Adjectives: []string{"Sunbaked", "Shifting", "Forgotten", "Scorched", "Golden", "Barren", "Red", "Endless"},
+		Nouns:      []string{"Dunes", "Oasis", "Ruins", "Flats", "Mesa", "Wastes"},
+	},
+	BiomeTundra: {
+		Adjectives: []string{"Frozen", "Pale", "Howling", "Bitter", "Glacial", "White", "Still", "Rimed"},
+		Nouns:      []string{"Lake", "Steppe", "Barrow", "Drift", "Expanse", "Fjord"},
+	},
+	BiomeSwamp: {
+		Adjectives: []string{"Murky", "Sunken", "Rotting", "Drowned", "Fetid", "Misty", "Black", "Weeping"},
+		Nouns:      []string{"Bog", "Mire", "Fen", "Marsh", "Hollow", "Bayou"},
+	},
+	BiomeCoast: {
+		Adjectives: []string{"Salt", "Stormy", "Wrecked", "Hidden", "Sandy", "Gull", "Shattered", "Moonlit"},
+		Nouns:      []string{"Cove", "Shore", "Bluff", "Harbor", "Strand", "Lighthouse"},
+	},
+	BiomeCaverns: {
+		Adjectives: []string{"Crystal", "Echoing", "Deep", "Forsaken", "Glittering", "Dripping", "Abandoned", "Haunted"},
+		Nouns:      []string{"Cave", "Mine", "Grotto", "Chasm", "Tunnels", "Vault"},
+	},
+}
+
+// regionPrefixes keep names unique once every adjective/noun pair of a
+// biome has been used.
+var regionPrefixes = []string{"Northern", "Southern", "Eastern", "Western", "Upper", "Lower", "Outer", "Inner"}
+
+// nameGenerator hands out unique names, drawing each biome's adjective/noun
+// pairs in a shuffled order.
+type nameGenerator struct {
+	rng   *rand.Rand
+	pool  NamePool
+	used  map[string]bool
+	pairs map[Biome][]string
+}
+
+func newNameGenerator(rng *rand.Rand, pool NamePool) *nameGenerator {
+	return &nameGenerator{rng: rng, pool: pool, used: make(map[string]bool), pairs: make(map[Biome][]string)}
+}
+
+func (n *nameGenerator) name(biome Biome) string {
+	pairs, ok := n.pairs[biome]
+	if !ok {
+		for _, adj := range n.pool.Adjectives(biome) {
+			for _, noun := range n.pool.Nouns(biome) {
+				pairs = append(pairs, adj+" "+noun)
+			}
+		}
+		n.rng.Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+		if len(pairs) == 0 {
+			pairs = []string{strings.ToUpper(string(biome[:1])) + string(biome[1:])}
+		}
+	}
+
+	for _, prefix := range append([]string{""}, regionPrefixes...) {
+		for _, pair := range pairs {
+			candidate := strings.TrimSpace(prefix + " " + pair)
+			if !n.used[candidate] {
+				n.used[candidate] = true
+				n.pairs[biome] = pairs
+				return candidate
+			}
+		}
+	}
+
+	// Only reachable with a tiny pool and a huge world. Numbering never
+	// runs out, so names stay unique however small the pool is.
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s %d", pairs[0], i)
+		if !n.used[candidate] {
+			n.used[candidate] = true
+			n.pairs[biome] = pairs
+			return candidate
+		}
+	}
+}
+
+// biomeText holds the phrases descriptions are assembled from.
+type biomeText struct {
+	openers    []string // "%s" is the location name
+	features   []string
+	atmosphere []string
+}
+
+var biomeTexts = map[Biome]biomeText{
+	BiomeForest: {
+		openers:    []string{"%s lies deep beneath the trees.", "The path winds into %s."},
+		features:   []string{"Gnarled roots twist across the ground.", "Shafts of light pierce the canopy.", "A ring of toadstools circles an old stump."},
+		atmosphere: []string{"The air smells of pine and damp earth.", "Birdsong falls silent as you pass."},
+	},
+	BiomeMountains: {
+		openers:    []string{"%s clings to the mountainside.", "The trail climbs steeply to %s."},
+		features:   []string{"Loose scree shifts underfoot.", "An old cairn marks the way.", "A narrow ledge overlooks the valley far below."},
+		atmosphere: []string{"A thin, cold wind whistles between the rocks.", "Clouds drift past below you."},
+	},
+	BiomeDesert: {
+		openers:    []string{"%s shimmers in the heat.", "Sand drifts across %s."},
+		features:   []string{"Half-buried columns jut from the sand.", "Bleached bones lie in the shade of a rock.", "A dry well gapes in the ground."},
+		atmosphere: []string{"The sun beats down without mercy.", "Hot wind hisses over the dunes."},
+	},
+	BiomeTundra: {
+		openers:    []string{"%s stretches out, white and empty.", "Snow crunches as you enter %s."},
+		features:   []string{"Ice groans somewhere beneath the snow.", "A frozen waterfall hangs from the rocks.", "Old tracks lead off into the drifts."},
+		atmosphere: []string{"Your breath hangs in the air.", "The silence is absolute."},
+	},
+	BiomeSwamp: {
+		openers:    []string{"%s sprawls between stagnant pools.", "The ground turns soft as you reach %s."},
+		features:   []string{"Dead trees rise from black water.", "Bubbles rise from the mud.", "A rotting boardwalk crosses the muck."},
+		atmosphere: []string{"Insects whine in the thick, wet air.", "A sour smell of decay hangs over everything."},
+	},
+	BiomeCoast: {
+		openers:    []string{"%s faces the open sea.", "Waves break below %s."},
+		features:   []string{"The ribs of a wrecked ship lie on the rocks.", "Tide pools glitter among the stones.", "Gulls wheel above a crumbling jetty."},
+		atmosphere: []string{"Salt spray stings your face.", "The roar of the surf never stops."},
+	},
+	BiomeCaverns: {
+		openers:    []string{"%s opens out in the dark.", "The tunnel widens into %s."},
+		features:   []string{"Crystals glint in the walls.", "Rusted rails run off into the gloom.", "Water drips steadily from the ceiling."},
+		atmosphere: []string{"Every sound echoes back at you.", "The darkness presses close."},
+	},
+}
+
+func pick(rng *rand.Rand, options []string) string {
+	return options[rng.Intn(len(options))]
+}
+
+// describe builds a location's description from its biome's phrases, its
+// features and the names of its exits.
+func describe(rng *rand.Rand, loc *Location, byID map[string]*Location) string {
+	text := biomeTexts[loc.Biome]
+	parts := []string{
+		fmt.Sprintf(pick(rng, text.openers), loc.Name),
+		pick(rng, text.features),
+		pick(rng, text.atmosphere),
+	}
+
+	switch {
+	case loc.SafeZone:
+		parts = append(parts, "An old ward keeps violence at bay here.")
+	case loc.Hazard != nil:
+		parts = append(parts, fmt.Sprintf("Beware the %s.", loc.Hazard.Kind))
+	}
+	if loc.Capacity > 0 {
+		parts = append(parts, fmt.Sprintf("There is only room for %d.", loc.Capacity))
+	}
+
+	exits := make([]string, 0, len(loc.Connections))
+	for _, id := range loc.Connections {
+		exits = append(exits, byID[id].Name)
+	}
+	switch len(exits) {
+	case 0:
+	case 1:
+		parts = append(parts, fmt.Sprintf("A single path leads to %s.", exits[0]))
+	default:
+		parts = append(parts, fmt.Sprintf("Paths lead to %s and %s.", strings.Join(exits[:len(exits)-1], ", "), exits[len(exits)-1]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// assignBiomes grows biome regions out from a few seed locations so that
+// neighbors tend to share a biome. Locations the seeds cannot reach start
+// regions of their own.
+func assignBiomes(rng *rand.Rand, locSlice []*Location, byID map[string]*Location) {
+	order := rng.Perm(len(Biomes))
+	nextBiome := 0
+	newBiome := func() Biome {
+		b := Biomes[order[nextBiome%len(order)]]
+		nextBiome++
+		return b
+	}
+
+	seeds := max(1, len(locSlice)/4)
+	var queue []*Location
+	for _, i := range rng.Perm(len(locSlice))[:seeds] {
+		locSlice[i].Biome = newBiome()
+		queue = append(queue, locSlice[i])
+	}
+
+	for {
+		for len(queue) > 0 {
+			loc := queue[0]
+			queue = queue[1:]
+			for _, id := range loc.Connections {
+				if next := byID[id]; next.Biome == "" {
+					next.Biome = loc.Biome
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		var orphan *Location
+		for _, loc := range locSlice {
+			if loc.Biome == "" {
+				orphan = loc
+				break
+			}
+		}
+		if orphan == nil {
+			return
+		}
+		orphan.Biome = newBiome()
+		queue = append(queue, orphan)
+	}
+}
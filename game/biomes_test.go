@@ -0,0 +1,47 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// tinyPool has a single adjective/noun pair for every biome.
+type tinyPool struct{}
+
+func (tinyPool) Adjectives(Biome) []string { return []string{"Lone"} }
+func (tinyPool) Nouns(Biome) []string      { return []string{"Rock"} }
+
+func TestNameGeneratorUnique(t *testing.T) {
+	tests := []struct {
+		name string
+		pool NamePool
+	}{
+		{"default pool", DefaultNames},
+		{"tiny pool", tinyPool{}},
+		{"empty pool", BiomeNames{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := newNameGenerator(rand.New(rand.NewSource(1)), tt.pool)
+			seen := make(map[string]bool)
+			for range 200 {
+				name := gen.name(BiomeForest)
+				if seen[name] {
+					t.Fatalf("name %q handed out twice", name)
+				}
+				seen[name] = true
+			}
+		})
+	}
+}
+
+func TestOptionsNamePool(t *testing.T) {
+	g := newTestGame(t, Options{Names: tinyPool{}, LocationCount: 5})
+	for _, loc := range g.Locations {
+		if !strings.Contains(loc.Name, "Lone Rock") {
+			t.Errorf("location %q was not named from the given pool", loc.Name)
+		}
+	}
+}
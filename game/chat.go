@@ -0,0 +1,231 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ChatChannel string
+
+const (
+	ChatSay     ChatChannel = "say"     // everyone at the sender's location
+	ChatWhisper ChatChannel = "whisper" // one player, anywhere
+	ChatShout   ChatChannel = "shout"   // the sender's location and its neighbors
+	ChatGlobal  ChatChannel = "global"  // everyone in the game
+	ChatParty   ChatChannel = "party"   // the sender's party
+)
+
+const (
+	MaxChatMessageLength = 280
+
+	// A player may send chatRateLimit messages in any chatRateWindow
+	chatRateLimit  = 5
+	chatRateWindow = 10 * time.Second
+
+	chatScrollback = 200
+)
+
+// ActionChat identifies chat in rate-limit errors.
+const ActionChat Action = "chat"
+
+type chatEntry struct {
+	event    Event
+	audience map[string]bool // players who received it; nil means everyone
+}
+
+// Chat sends a message on channel. targetID names the recipient of a whisper
+// and is ignored otherwise.
+func (g *Game) Chat(playerID string, channel ChatChannel, targetID, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("message is empty")
+	}
+	if utf8.RuneCountInString(text) > MaxChatMessageLength {
+		return fmt.Errorf("message exceeds %d characters", MaxChatMessageLength)
+	}
+
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if now.Before(player.mutedUntil) {
+		g.Mu.Unlock()
+		return fmt.Errorf("you are muted for another %s", player.mutedUntil.Sub(now).Round(time.Second))
+	}
+
+	if err := player.checkChatRate(now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	// Filters run before anything is broadcast or logged
+	filtered := g.Filter.Apply(text)
+	if filtered.Blocked {
+		g.audit(AuditEntry{
+			Kind:      AuditFilterBlocked,
+			ActorID:   playerID,
+			Detail:    text,
+			Timestamp: now,
+		})
+		g.Mu.Unlock()
+		return fmt.Errorf("message blocked by chat filter")
+	}
+	text = filtered.Text
+
+	event := Event{
+		ID:        g.nextEventID(),
+		PlayerID:  playerID,
+		Location:  player.CurrentLocation,
+		Text:      text,
+		Timestamp: now,
+	}
+	var audience map[string]bool
+	var revealed []Event
+
+	switch channel {
+	case ChatSay:
+		event.Type = EventChatSay
+		event.Message = fmt.Sprintf("%s says: %s", player.Name, text)
+		audience = g.playersAt(player.CurrentLocation)
+
+	case ChatWhisper:
+		target := g.Players[targetID]
+		if target == nil {
+			g.Mu.Unlock()
+			return fmt.Errorf("player not found")
+		}
+		event.Type = EventChatWhisper
+		event.TargetID = targetID
+		event.Message = fmt.Sprintf("%s whispers to %s: %s", player.Name, target.Name, text)
+		event.Recipients = []string{playerID, targetID}
+		audience = map[string]bool{playerID: true, targetID: true}
+
+	case ChatShout:
+		event.Type = EventChatShout
+		event.Message = fmt.Sprintf("%s shouts: %s", player.Name, text)
+		event.Locations = g.Locations[player.CurrentLocation].Connections
+		audience = g.playersAt(player.CurrentLocation, event.Locations...)
+
+	case ChatParty:
+		party := g.parties[player.PartyID]
+		if party == nil {
+			g.Mu.Unlock()
+			return fmt.Errorf("not in a party")
+		}
+		event.Type = EventChatParty
+		event.TargetID = party.ID
+		event.Message = fmt.Sprintf("[party] %s: %s", player.Name, text)
+		event.Recipients = append([]string(nil), party.Members...)
+		audience = make(map[string]bool)
+		for _, id := range party.Members {
+			audience[id] = true
+		}
+
+	case ChatGlobal:
+		event.Type = EventChatGlobal
+		event.Message = fmt.Sprintf("%s: %s", player.Name, text)
+		event.Global = true
+
+	default:
+		g.Mu.Unlock()
+		return fmt.Errorf("unknown chat channel: %s", channel)
+	}
+
+	if len(filtered.Masked) > 0 {
+		g.audit(AuditEntry{
+			Kind:      AuditFilterMasked,
+			ActorID:   playerID,
+			EventIDs:  []string{event.ID},
+			Detail:    strings.Join(filtered.Masked, ", "),
+			Timestamp: now,
+		})
+	}
+	if len(filtered.Flagged) > 0 {
+		g.audit(AuditEntry{
+			Kind:      AuditFilterFlagged,
+			ActorID:   playerID,
+			EventIDs:  []string{event.ID},
+			Detail:    strings.Join(filtered.Flagged, ", "),
+			Timestamp: now,
+		})
+	}
+
+	player.chatSent = append(player.chatSent, now)
+	g.chatLog = append(g.chatLog, chatEntry{event: event, audience: audience})
+	if len(g.chatLog) > chatScrollback {
+		g.chatLog = g.chatLog[len(g.chatLog)-chatScrollback:]
+	}
+	// Speaking aloud gives a hidden player away
+	if player.Hidden && (channel == ChatSay || channel == ChatShout) {
+		revealed = g.reveal(player, nil)
+	}
+	g.Mu.Unlock()
+
+	g.broadcastEvents(append(revealed, event))
+	return nil
+}
+
+// checkChatRate enforces the per-player chat rate limit. The caller must hold
+// g.Mu.
+func (p *Player) checkChatRate(now time.Time) error {
+	recent := p.chatSent[:0]
+	for _, t := range p.chatSent {
+		if now.Sub(t) < chatRateWindow {
+			recent = append(recent, t)
+		}
+	}
+	p.chatSent = recent
+
+	if len(recent) >= chatRateLimit {
+		return &CooldownError{Action: ActionChat, RetryAfter: recent[0].Add(chatRateWindow).Sub(now)}
+	}
+	return nil
+}
+
+// playersAt returns the set of players standing at any of the given
+// locations. The caller must hold g.Mu.
+func (g *Game) playersAt(location string, more ...string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, p := range g.Players {
+		if p.CurrentLocation == location || contains(more, p.CurrentLocation) {
+			ids[p.ID] = true
+		}
+	}
+	return ids
+}
+
+// ChatHistory returns up to limit of the most recent chat messages the player
+// received, oldest first. If sinceID is set only messages after that event ID
+// are returned.
+func (g *Game) ChatHistory(playerID, sinceID string, limit int) []Event {
+	var since uint64
+	if sinceID != "" {
+		since, _ = strconv.ParseUint(sinceID, 10, 64)
+	}
+
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	var history []Event
+	for _, entry := range g.chatLog {
+		if id, _ := strconv.ParseUint(entry.event.ID, 10, 64); id <= since {
+			continue
+		}
+		if entry.audience != nil && !entry.audience[playerID] {
+			continue
+		}
+		history = append(history, entry.event)
+	}
+
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel ChatChannel
+		target  string
+		text    string
+	}{
+		{"empty", ChatSay, "", "   "},
+		{"too long", ChatSay, "", strings.Repeat("a", MaxChatMessageLength+1)},
+		{"whisper to nobody", ChatWhisper, "nobody", "hi"},
+		{"party without a party", ChatParty, "", "hi"},
+		{"unknown channel", "yell", "", "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			addTestPlayer(t, g, "a")
+
+			if err := g.Chat("a", tt.channel, tt.target, tt.text); err == nil {
+				t.Error("Chat() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestChatRateLimit(t *testing.T) {
+	g := newTestGame(t, Options{})
+	addTestPlayer(t, g, "a")
+
+	for i := range chatRateLimit {
+		if err := g.Chat("a", ChatSay, "", "hello"); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+	var cooldownErr *CooldownError
+	if err := g.Chat("a", ChatSay, "", "hello"); !errors.As(err, &cooldownErr) || cooldownErr.Action != ActionChat {
+		t.Errorf("message over the limit: error = %v, want a chat cooldown", err)
+	}
+}
+
+func TestChatHistoryAudience(t *testing.T) {
+	g := newTestGame(t, Options{})
+	addTestPlayer(t, g, "a")
+	addTestPlayer(t, g, "b")
+	far := addTestPlayer(t, g, "far")
+	far.CurrentLocation = "elsewhere"
+
+	messages := []struct {
+		from, to string
+		channel  ChatChannel
+	}{
+		{"a", "", ChatSay},
+		{"a", "b", ChatWhisper},
+		{"b", "", ChatGlobal},
+	}
+	for _, m := range messages {
+		if err := g.Chat(m.from, m.channel, m.to, "hi"); err != nil {
+			t.Fatalf("%s: %v", m.channel, err)
+		}
+	}
+
+	tests := []struct {
+		playerID string
+		want     int
+	}{
+		{"a", 3},
+		{"b", 3},
+		{"far", 1}, // only the global message
+	}
+	for _, tt := range tests {
+		if got := len(g.ChatHistory(tt.playerID, "", 0)); got != tt.want {
+			t.Errorf("%s sees %d messages, want %d", tt.playerID, got, tt.want)
+		}
+	}
+}
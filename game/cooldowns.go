@@ -0,0 +1,80 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionMove   Action = "move"
+	ActionAttack Action = "attack"
+	ActionFlee   Action = "flee"
+	ActionHide   Action = "hide"
+	ActionSneak  Action = "sneak"
+	ActionSearch Action = "search"
+	ActionUse    Action = "use"
+)
+
+// ActionCooldowns is how long a player must wait after an action before
+// repeating it. The move cooldown doubles as travel time: a player cannot
+// set off again until the previous move has resolved.
+var ActionCooldowns = map[Action]time.Duration{
+	ActionMove:   1500 * time.Millisecond,
+	ActionAttack: 1000 * time.Millisecond,
+	ActionFlee:   2000 * time.Millisecond,
+	ActionHide:   3000 * time.Millisecond,
+	ActionSneak:  3000 * time.Millisecond,
+	ActionSearch: 2000 * time.Millisecond,
+	ActionUse:    1000 * time.Millisecond,
+}
+
+// CooldownError is returned when a player attempts an action that is still
+// cooling down.
+type CooldownError struct {
+	Action     Action
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%s is on cooldown, retry after %dms", e.Action, e.RetryAfter.Milliseconds())
+}
+
+// checkCooldown returns a *CooldownError if the action is not ready yet.
+// The caller must hold g.Mu.
+func (p *Player) checkCooldown(action Action, now time.Time) error {
+	readyAt, ok := p.cooldowns[action]
+	if !ok || !now.Before(readyAt) {
+		return nil
+	}
+	return &CooldownError{Action: action, RetryAfter: readyAt.Sub(now)}
+}
+
+// startCooldown puts the action on cooldown, scaled by scale and by the
+// player's status effects. The caller must hold g.Mu.
+func (p *Player) startCooldown(action Action, now time.Time, scale float64) {
+	duration := time.Duration(float64(ActionCooldowns[action]) * scale)
+	for _, e := range p.Effects {
+		if scale := EffectDefinitions[e.Kind].CooldownScale; scale > 0 {
+			duration = time.Duration(float64(duration) * scale)
+		}
+	}
+
+	if p.cooldowns == nil {
+		p.cooldowns = make(map[Action]time.Time)
+	}
+	p.cooldowns[action] = now.Add(duration)
+}
+
+// CooldownsRemaining returns the milliseconds left on each action that is
+// still cooling down.
+func (p *Player) CooldownsRemaining(now time.Time) map[Action]int64 {
+	remaining := make(map[Action]int64)
+	for action, readyAt := range p.cooldowns {
+		if now.Before(readyAt) {
+			remaining[action] = readyAt.Sub(now).Milliseconds()
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCooldowns(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		effects []*StatusEffect
+		after   time.Duration
+		wantErr bool
+	}{
+		{"still cooling down", nil, 500 * time.Millisecond, true},
+		{"ready again", nil, ActionCooldowns[ActionAttack], false},
+		{"haste shortens it", []*StatusEffect{{Kind: EffectHaste, Stacks: 1, ExpiresAt: now.Add(time.Minute)}}, 800 * time.Millisecond, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Player{Effects: tt.effects}
+			p.startCooldown(ActionAttack, now, 1)
+
+			err := p.checkCooldown(ActionAttack, now.Add(tt.after))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkCooldown() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var cooldownErr *CooldownError
+			if err != nil && (!errors.As(err, &cooldownErr) || cooldownErr.RetryAfter <= 0) {
+				t.Errorf("error %v carries no retry delay", err)
+			}
+			if other := p.checkCooldown(ActionMove, now); other != nil {
+				t.Errorf("cooldown leaked onto another action: %v", other)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+type EffectKind string
+
+const (
+	EffectPoison EffectKind = "poison"
+	EffectStun   EffectKind = "stun"
+	EffectBleed  EffectKind = "bleed"
+	EffectHaste  EffectKind = "haste"
+	EffectRegen  EffectKind = "regeneration"
+)
+
+// StackRule controls what happens when an effect is applied to a player
+// who already carries it.
+type StackRule int
+
+const (
+	StackRefresh   StackRule = iota // keep one stack, reset the duration
+	StackIntensify                  // add a stack (up to MaxStacks), reset the duration
+	StackExtend                     // keep one stack, add Duration to the time remaining
+)
+
+// EffectDefinition describes how an effect behaves. Stat and damage
+// modifiers are applied once per stack.
+type EffectDefinition struct {
+	Kind          EffectKind
+	Duration      time.Duration
+	Stacking      StackRule
+	MaxStacks     int
+	DamagePerTick int
+	HealPerTick   int // capped at the ruleset's starting health
+	StrengthMod   int
+	DexterityMod  int
+	DamageMod     int     // added to the carrier's outgoing attack damage
+	Incapacitates bool    // carrier can neither move nor attack
+	CooldownScale float64 // multiplies action cooldowns; 0 leaves them unchanged
+}
+
+var EffectDefinitions = map[EffectKind]EffectDefinition{
+	EffectPoison: {
+		Kind:          EffectPoison,
+		Duration:      10 * time.Second,
+		Stacking:      StackIntensify,
+		MaxStacks:     5,
+		DamagePerTick: 2,
+		StrengthMod:   -1,
+	},
+	EffectStun: {
+		Kind:          EffectStun,
+		Duration:      3 * time.Second,
+		Stacking:      StackRefresh,
+		MaxStacks:     1,
+		Incapacitates: true,
+	},
+	EffectBleed: {
+		Kind:          EffectBleed,
+		Duration:      6 * time.Second,
+		Stacking:      StackIntensify,
+		MaxStacks:     3,
+		DamagePerTick: 1,
+		DamageMod:     -1,
+	},
+	EffectHaste: {
+		Kind:          EffectHaste,
+		Duration:      15 * time.Second,
+		Stacking:      StackExtend,
+		MaxStacks:     1,
+		DexterityMod:  3,
+		CooldownScale: 0.7,
+	},
+	EffectRegen: {
+		Kind:        EffectRegen,
+		Duration:    5 * time.Second,
+		Stacking:    StackRefresh,
+		MaxStacks:   1,
+		HealPerTick: 5,
+	},
+}
+
+type StatusEffect struct {
+	Kind      EffectKind `json:"kind"`
+	Stacks    int        `json:"stacks"`
+	SourceID  string     `json:"source_id,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// ApplyEffect puts an effect on a player and broadcasts it. sourceID is the
+// player (or item) responsible and may be empty.
+func (g *Game) ApplyEffect(playerID string, kind EffectKind, sourceID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	event, err := g.applyEffect(player, kind, sourceID, time.Now())
+	g.Mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	g.BroadcastEvent(event)
+	return nil
+}
+
+// applyEffect adds or stacks an effect on player. The caller must hold g.Mu.
+func (g *Game) applyEffect(player *Player, kind EffectKind, sourceID string, now time.Time) (Event, error) {
+	def, ok := EffectDefinitions[kind]
+	if !ok {
+		return Event{}, fmt.Errorf("unknown effect: %s", kind)
+	}
+	if player.Health <= 0 {
+		return Event{}, fmt.Errorf("player is defeated")
+	}
+
+	var effect *StatusEffect
+	for _, e := range player.Effects {
+		if e.Kind == kind {
+			effect = e
+			break
+		}
+	}
+
+	if effect == nil {
+		effect = &StatusEffect{Kind: kind, Stacks: 1, SourceID: sourceID, ExpiresAt: now.Add(def.Duration)}
+		player.Effects = append(player.Effects, effect)
+	} else {
+		switch def.Stacking {
+		case StackIntensify:
+			if effect.Stacks < def.MaxStacks {
+				effect.Stacks++
+			}
+			effect.ExpiresAt = now.Add(def.Duration)
+		case StackExtend:
+			effect.ExpiresAt = effect.ExpiresAt.Add(def.Duration)
+		default:
+			effect.ExpiresAt = now.Add(def.Duration)
+		}
+		effect.SourceID = sourceID
+	}
+
+	return Event{
+		Type:      EventEffectApplied,
+		PlayerID:  player.ID,
+		TargetID:  sourceID,
+		Location:  player.CurrentLocation,
+		Message:   fmt.Sprintf("%s is affected by %s (x%d)", player.Name, kind, effect.Stacks),
+		Concealed: player.Hidden,
+	}, nil
+}
+
+// tickEffects applies per-tick damage and expires finished effects for
+// every player. Hidden players are the only ones to see their own effects.
+// The caller must hold g.Mu.
+func (g *Game) tickEffects(now time.Time) []Event {
+	var events []Event
+
+	for _, player := range g.Players {
+		if len(player.Effects) == 0 {
+			continue
+		}
+
+		var lastSource string
+		alive := player.Health > 0
+		remaining := player.Effects[:0]
+		for _, effect := range player.Effects {
+			def := EffectDefinitions[effect.Kind]
+
+			if def.DamagePerTick > 0 && player.Health > 0 {
+				damage := def.DamagePerTick * effect.Stacks
+				player.Health -= damage
+				lastSource = effect.SourceID
+				events = append(events, Event{
+					Type:      EventEffectTick,
+					PlayerID:  player.ID,
+					Location:  player.CurrentLocation,
+					Message:   fmt.Sprintf("%s takes %d %s damage", player.Name, damage, effect.Kind),
+					Concealed: player.Hidden,
+				})
+			}
+			if def.HealPerTick > 0 && player.Health > 0 {
+				healed := def.HealPerTick * effect.Stacks
+				if limit := g.Ruleset.StartingHealth; limit > 0 {
+					healed = min(healed, max(limit-player.Health, 0))
+				}
+				if healed > 0 {
+					player.Health += healed
+					events = append(events, Event{
+						Type:      EventEffectTick,
+						PlayerID:  player.ID,
+						Location:  player.CurrentLocation,
+						Message:   fmt.Sprintf("%s recovers %d health", player.Name, healed),
+						Concealed: player.Hidden,
+					})
+				}
+			}
+
+			if !now.Before(effect.ExpiresAt) {
+				events = append(events, Event{
+					Type:      EventEffectExpired,
+					PlayerID:  player.ID,
+					Location:  player.CurrentLocation,
+					Message:   fmt.Sprintf("%s is no longer affected by %s", player.Name, effect.Kind),
+					Concealed: player.Hidden,
+				})
+				continue
+			}
+			remaining = append(remaining, effect)
+		}
+		player.Effects = remaining
+
+		// Whoever applied the effect that dealt the final tick gets the
+		// credit. A player who was already down is not defeated again.
+		if alive && player.Health <= 0 {
+			events = append(events, g.defeat(player, lastSource)...)
+		}
+	}
+
+	return events
+}
+
+// cleanseEffect removes every stack of kind from player and reports whether
+// there was anything to remove. The caller must hold g.Mu.
+func (g *Game) cleanseEffect(player *Player, kind EffectKind) bool {
+	for i, e := range player.Effects {
+		if e.Kind == kind {
+			player.Effects = append(player.Effects[:i], player.Effects[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
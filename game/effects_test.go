@@ -0,0 +1,140 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickEffectsDefeatsOnce(t *testing.T) {
+	tests := []struct {
+		name        string
+		health      int
+		wantDefeats int
+	}{
+		{"survives the tick", 50, 0},
+		{"killed by the tick", 2, 1},
+		{"already defeated", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			p := addTestPlayer(t, g, "p1")
+			now := time.Now()
+
+			g.Mu.Lock()
+			defer g.Mu.Unlock()
+			p.Health = tt.health
+			p.Effects = []*StatusEffect{{Kind: EffectPoison, Stacks: 1, ExpiresAt: now.Add(time.Minute)}}
+
+			events := g.tickEffects(now)
+			if got := countEvents(events, EventPlayerLeft); got != tt.wantDefeats {
+				t.Errorf("defeats = %d, want %d", got, tt.wantDefeats)
+			}
+		})
+	}
+}
+
+func TestTickEffectsRegenCapped(t *testing.T) {
+	g := newTestGame(t, Options{})
+	p := addTestPlayer(t, g, "p1")
+	now := time.Now()
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	p.Health = g.Ruleset.StartingHealth - 2
+	p.Effects = []*StatusEffect{{Kind: EffectRegen, Stacks: 1, ExpiresAt: now.Add(time.Minute)}}
+
+	g.tickEffects(now)
+	if p.Health != g.Ruleset.StartingHealth {
+		t.Errorf("health = %d, want %d", p.Health, g.Ruleset.StartingHealth)
+	}
+}
+
+func TestIncapacitated(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    EffectKind
+		expires time.Duration
+		want    bool
+	}{
+		{"active stun", EffectStun, time.Minute, true},
+		{"expired stun", EffectStun, -time.Second, false},
+		{"poison", EffectPoison, time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Player{Effects: []*StatusEffect{{Kind: tt.kind, Stacks: 1, ExpiresAt: time.Now().Add(tt.expires)}}}
+			if got := p.Incapacitated(); got != tt.want {
+				t.Errorf("Incapacitated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		item      string
+		carrying  []EffectKind
+		health    int
+		wantErr   bool
+		wantAfter []EffectKind
+	}{
+		{"health potion", "Health Potion", []EffectKind{EffectBleed}, 50, false, []EffectKind{EffectRegen}},
+		{"antidote", "Antidote", []EffectKind{EffectPoison, EffectHaste}, 50, false, []EffectKind{EffectHaste}},
+		{"not usable", "Torch", nil, 50, true, nil},
+		{"defeated", "Antidote", nil, 0, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			p := addTestPlayer(t, g, "p1")
+			item := NewItem(tt.item)
+
+			g.Mu.Lock()
+			p.Health = tt.health
+			p.Inventory = []*Item{item}
+			p.Effects = nil
+			for _, kind := range tt.carrying {
+				p.Effects = append(p.Effects, &StatusEffect{Kind: kind, Stacks: 1, ExpiresAt: time.Now().Add(time.Minute)})
+			}
+			g.Mu.Unlock()
+
+			err := g.UseItem("p1", item.ID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UseItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			g.Mu.RLock()
+			defer g.Mu.RUnlock()
+			if tt.wantErr {
+				if len(p.Inventory) != 1 {
+					t.Errorf("a failed use consumed the item")
+				}
+				return
+			}
+			if len(p.Inventory) != 0 {
+				t.Errorf("item was not consumed")
+			}
+			if len(p.Effects) != len(tt.wantAfter) {
+				t.Fatalf("effects = %d, want %d", len(p.Effects), len(tt.wantAfter))
+			}
+			for i, kind := range tt.wantAfter {
+				if p.Effects[i].Kind != kind {
+					t.Errorf("effect %d = %s, want %s", i, p.Effects[i].Kind, kind)
+				}
+			}
+		})
+	}
+
+	t.Run("missing item", func(t *testing.T) {
+		g := newTestGame(t, Options{})
+		addTestPlayer(t, g, "p1")
+		if err := g.UseItem("p1", "nope"); err == nil {
+			t.Error("using an item not held succeeded")
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// EngagementTimeout is how long two players stay engaged after their last
+// exchange of blows.
+const EngagementTimeout = 15 * time.Second
+
+// engage marks two players as fighting each other. The caller must hold g.Mu.
+func engage(a, b *Player, now time.Time) {
+	if a.engaged == nil {
+		a.engaged = make(map[string]time.Time)
+	}
+	if b.engaged == nil {
+		b.engaged = make(map[string]time.Time)
+	}
+	a.engaged[b.ID] = now
+	b.engaged[a.ID] = now
+}
+
+// disengage ends every engagement the player is part of. The caller must
+// hold g.Mu.
+func (g *Game) disengage(player *Player) {
+	for id := range player.engaged {
+		if other := g.Players[id]; other != nil {
+			delete(other.engaged, player.ID)
+		}
+	}
+	player.engaged = nil
+}
+
+// opponents returns the living players still engaged with player at the
+// same location, in ID order. The caller must hold g.Mu.
+func (g *Game) opponents(player *Player, now time.Time) []*Player {
+	var result []*Player
+	for id, since := range player.engaged {
+		other := g.Players[id]
+		if other == nil || other.Health <= 0 || other.CurrentLocation != player.CurrentLocation ||
+			now.Sub(since) > EngagementTimeout {
+			continue
+		}
+		result = append(result, other)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// EngagedWith returns the IDs of the players this player is currently
+// fighting. The caller must hold g.Mu.
+func (g *Game) EngagedWith(player *Player, now time.Time) []string {
+	ids := make([]string, 0, len(player.engaged))
+	for _, other := range g.opponents(player, now) {
+		ids = append(ids, other.ID)
+	}
+	return ids
+}
+
+// opportunityAttacks gives every engaged opponent a free attack on a player
+// leaving the fight. The caller must hold g.Mu.
+func (g *Game) opportunityAttacks(player *Player, now time.Time) []Event {
+	var events []Event
+	for _, opponent := range g.opponents(player, now) {
+		if player.Health <= 0 {
+			break
+		}
+
+		strike, err := g.attackPlayer(opponent.ID, player.ID, now)
+		if err != nil {
+			continue
+		}
+		events = append(events, Event{
+			Type:     EventOpportunityAttack,
+			PlayerID: opponent.ID,
+			TargetID: player.ID,
+			Location: player.CurrentLocation,
+			Message:  fmt.Sprintf("%s strikes at %s as they turn to leave", opponent.Name, player.Name),
+		})
+		events = append(events, strike...)
+	}
+	return events
+}
+
+func (g *Game) Flee(playerID, locationID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := g.beginAction(player, ActionFlee, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.flee(playerID, locationID, now)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionFlee, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// flee tries to break away from every engaged opponent. The chance starts at
+// 50% and shifts 5% per point of Dexterity the player has over (or under)
+// their quickest opponent, clamped to 10-90%. Success moves the player to
+// locationID, or a random neighbor if it is empty, without attacks of
+// opportunity; failure leaves them in place and open to those attacks. The
+// caller must hold g.Mu.
+func (g *Game) flee(playerID, locationID string, now time.Time) ([]Event, error) {
+	player := g.Players[playerID]
+	if player == nil {
+		return nil, fmt.Errorf("player not found")
+	}
+
+	opponents := g.opponents(player, now)
+	if len(opponents) == 0 {
+		return nil, fmt.Errorf("not engaged in combat")
+	}
+
+	if locationID == "" {
+		var exits []string
+		for _, id := range g.Locations[player.CurrentLocation].Connections {
+			if g.checkMove(player, id) == nil {
+				exits = append(exits, id)
+			}
+		}
+		if len(exits) == 0 {
+			return nil, fmt.Errorf("nowhere to flee")
+		}
+		locationID = exits[rand.Intn(len(exits))]
+	}
+
+	if locationID == player.CurrentLocation {
+		return nil, fmt.Errorf("must flee to another location")
+	}
+
+	if err := g.checkMove(player, locationID); err != nil {
+		return nil, err
+	}
+
+	fastest := 0
+	for _, o := range opponents {
+		fastest = max(fastest, o.EffectiveDexterity())
+	}
+	chance := min(max(50+(player.EffectiveDexterity()-fastest)*5, 10), 90)
+
+	if rand.Intn(100) >= chance {
+		events := []Event{{
+			Type:     EventFleeFailed,
+			PlayerID: playerID,
+			Location: player.CurrentLocation,
+			Message:  fmt.Sprintf("%s tried to flee but was cut off!", player.Name),
+		}}
+		return append(events, g.opportunityAttacks(player, now)...), nil
+	}
+
+	events := []Event{{
+		Type:     EventPlayerFled,
+		PlayerID: playerID,
+		Location: player.CurrentLocation,
+		Message:  fmt.Sprintf("%s fled the fight!", player.Name),
+	}}
+	return append(events, g.relocate(player, locationID)...), nil
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpponents(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(g *Game, other *Player)
+		since   time.Duration
+		want    int
+	}{
+		{"engaged", func(*Game, *Player) {}, 0, 1},
+		{"engagement timed out", func(*Game, *Player) {}, EngagementTimeout + time.Second, 0},
+		{"opponent defeated", func(_ *Game, o *Player) { o.Health = 0 }, 0, 0},
+		{"opponent left", func(g *Game, o *Player) { o.CurrentLocation = "elsewhere" }, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			a := addTestPlayer(t, g, "a")
+			b := addTestPlayer(t, g, "b")
+			now := time.Now()
+
+			engage(a, b, now.Add(-tt.since))
+			tt.prepare(g, b)
+
+			if got := len(g.opponents(a, now)); got != tt.want {
+				t.Errorf("opponents = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFleeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		engaged bool
+		target  func(g *Game) string
+	}{
+		{"not engaged", false, func(*Game) string { return "" }},
+		{"same location", true, firstLocation},
+		{"unknown location", true, func(*Game) string { return "nowhere" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			a := addTestPlayer(t, g, "a")
+			b := addTestPlayer(t, g, "b")
+			if tt.engaged {
+				engage(a, b, time.Now())
+			}
+
+			if err := g.Flee("a", tt.target(g)); err == nil {
+				t.Error("Flee() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestMoveProvokesOpportunityAttacks(t *testing.T) {
+	g := newTestGame(t, Options{})
+	a := addTestPlayer(t, g, "a")
+	b := addTestPlayer(t, g, "b")
+	away := openNeighbor(t, g)
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	now := time.Now()
+	engage(a, b, now)
+
+	events, err := g.movePlayer("a", away, now)
+	if err != nil {
+		t.Fatalf("movePlayer() error = %v", err)
+	}
+	if countEvents(events, EventOpportunityAttack) != 1 {
+		t.Errorf("want one opportunity attack, got %+v", events)
+	}
+	if len(a.engaged) != 0 || len(b.engaged) != 0 {
+		t.Error("leaving did not end the engagement")
+	}
+}
@@ -10,14 +10,69 @@ const (
 	EventPlayerMoved  EventType = "player_moved"
 	EventPlayerAttack EventType = "player_attack"
 	EventNPCAction    EventType = "npc_action"
+
+	EventEffectApplied EventType = "effect_applied"
+	EventEffectTick    EventType = "effect_tick"
+	EventEffectExpired EventType = "effect_expired"
+	EventHazardDamage  EventType = "hazard_damage"
+	EventItemUsed      EventType = "item_used"
+
+	EventPhaseChanged   EventType = "phase_changed"
+	EventWeatherChanged EventType = "weather_changed"
+
+	EventRoundStarted EventType = "round_started"
+	EventTurnStarted  EventType = "turn_started"
+	EventTurnEnded    EventType = "turn_ended"
+
+	EventOpportunityAttack EventType = "opportunity_attack"
+	EventPlayerFled        EventType = "player_fled"
+	EventFleeFailed        EventType = "flee_failed"
+
+	EventTickResolved  EventType = "tick_resolved"
+	EventCommandFailed EventType = "command_failed"
+
+	EventChatSay     EventType = "chat_say"
+	EventChatWhisper EventType = "chat_whisper"
+	EventChatShout   EventType = "chat_shout"
+	EventChatGlobal  EventType = "chat_global"
+
+	EventPlayerMuted  EventType = "player_muted"
+	EventPlayerKicked EventType = "player_kicked"
+
+	EventPartyInvite EventType = "party_invite"
+	EventPartyJoined EventType = "party_joined"
+	EventPartyLeft   EventType = "party_left"
+	EventChatParty   EventType = "chat_party"
+	EventTeamScored  EventType = "team_scored"
+	EventTeamWon     EventType = "team_won"
+
+	EventTradeOpened    EventType = "trade_opened"
+	EventTradeUpdated   EventType = "trade_updated"
+	EventTradeCompleted EventType = "trade_completed"
+	EventTradeCancelled EventType = "trade_cancelled"
+
+	EventQuestStarted   EventType = "quest_started"
+	EventQuestProgress  EventType = "quest_progress"
+	EventQuestCompleted EventType = "quest_completed"
+
+	EventPlayerHidden   EventType = "player_hidden"
+	EventPlayerRevealed EventType = "player_revealed"
+	EventSearchFailed   EventType = "search_failed"
 )
 
 type Event struct {
+	ID        string    `json:"id,omitempty"`
 	Type      EventType `json:"type"`
 	PlayerID  string    `json:"player_id,omitempty"`
 	Location  string    `json:"location,omitempty"`
 	TargetID  string    `json:"target_id,omitempty"`
 	Message   string    `json:"message"`
+	Text      string    `json:"text,omitempty"`   // raw chat text
+	Events    []Event   `json:"events,omitempty"` // batched results, e.g. tick_resolved
 	Timestamp time.Time `json:"timestamp"`
 	Global    bool      `json:"-"`
+
+	Locations  []string `json:"-"` // extra locations that also see the event
+	Recipients []string `json:"-"` // if set, only these players see the event
+	Concealed  bool     `json:"-"` // if set, only PlayerID sees the event
 }
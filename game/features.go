@@ -0,0 +1,231 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Hazard damages everyone standing in a location on every game tick.
+type Hazard struct {
+	Kind   string     `json:"kind"`
+	Damage int        `json:"damage"`
+	Effect EffectKind `json:"effect,omitempty"` // also applied each tick, if set
+}
+
+// Lock bars a passage unless the player carries KeyItem, or has at least
+// MinValue in Stat ("strength" or "dexterity").
+type Lock struct {
+	KeyItem  string `json:"key_item,omitempty"`
+	Stat     string `json:"stat,omitempty"`
+	MinValue int    `json:"min_value,omitempty"`
+}
+
+var hazardKinds = []Hazard{
+	{Kind: "scorching heat", Damage: 1},
+	{Kind: "poison gas", Damage: 1, Effect: EffectPoison},
+	{Kind: "biting cold", Damage: 2},
+}
+
+// checkLock reports why player cannot pass lock, or nil if they can.
+func checkLock(player *Player, lock *Lock, destination *Location) error {
+	if lock == nil {
+		return nil
+	}
+
+	if lock.KeyItem != "" {
+		if player.HasItemNamed(lock.KeyItem) {
+			return nil
+		}
+		return fmt.Errorf("the passage to %s is locked: requires %s", destination.Name, lock.KeyItem)
+	}
+
+	value := 0
+	switch lock.Stat {
+	case "strength":
+		value = player.EffectiveStrength()
+	case "dexterity":
+		value = player.EffectiveDexterity()
+	}
+	if value >= lock.MinValue {
+		return nil
+	}
+	return fmt.Errorf("the passage to %s requires %s %d (you have %d)", destination.Name, lock.Stat, lock.MinValue, value)
+}
+
+// occupancy counts the living players at a location. The caller must hold
+// g.Mu.
+func (g *Game) occupancy(locationID string) int {
+	n := 0
+	for _, p := range g.Players {
+		if p.CurrentLocation == locationID && p.Health > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// crowdingPenalty is the dodge chance lost in a location that is nearly or
+// completely full. Locations without a capacity never get crowded. The
+// caller must hold g.Mu.
+func (g *Game) crowdingPenalty(loc *Location) int {
+	if loc == nil || loc.Capacity <= 0 {
+		return 0
+	}
+
+	occupants := g.occupancy(loc.ID)
+	switch {
+	case occupants >= loc.Capacity:
+		return 10
+	case occupants*4 >= loc.Capacity*3:
+		return 5
+	}
+	return 0
+}
+
+// tickHazards damages players standing in hazardous locations. Damage to a
+// hidden player is only shown to them. The caller must hold g.Mu.
+func (g *Game) tickHazards(now time.Time) []Event {
+	var events []Event
+	for _, player := range g.Players {
+		loc := g.Locations[player.CurrentLocation]
+		if loc == nil || loc.Hazard == nil || player.Health <= 0 {
+			continue
+		}
+
+		player.Health -= loc.Hazard.Damage
+		events = append(events, Event{
+			Type:      EventHazardDamage,
+			PlayerID:  player.ID,
+			Location:  loc.ID,
+			Message:   fmt.Sprintf("%s suffers %d damage from the %s", player.Name, loc.Hazard.Damage, loc.Hazard.Kind),
+			Concealed: player.Hidden,
+		})
+
+		if player.Health <= 0 {
+			events = append(events, g.defeat(player, "")...)
+			continue
+		}
+
+		if loc.Hazard.Effect != "" {
+			if event, err := g.applyEffect(player, loc.Hazard.Effect, "", now); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+// addFeatures gives a generated world its safe zone, hazards, locked
+// passages and a one-way passage. Features that could leave a location
+// without a usable way out are skipped.
+func addFeatures(rng *rand.Rand, locSlice []*Location) {
+	if len(locSlice) < 3 {
+		return
+	}
+
+	order := rng.Perm(len(locSlice))
+
+	// One safe zone, then hazards elsewhere
+	locSlice[order[0]].SafeZone = true
+	for _, i := range order[1:min(3, len(order))] {
+		hazard := hazardKinds[rng.Intn(len(hazardKinds))]
+		locSlice[i].Hazard = &hazard
+	}
+
+	// Cramped places only fit a few players
+	for _, loc := range locSlice {
+		if rng.Intn(100) < 40 {
+			loc.Capacity = rng.Intn(5) + 2 // 2-6
+		}
+	}
+
+	byID := make(map[string]*Location, len(locSlice))
+	for _, loc := range locSlice {
+		byID[loc.ID] = loc
+	}
+
+	// Locked doors work in both directions and only go on passages between
+	// locations that have another way out
+	locks := []Lock{
+		{KeyItem: "Iron Key"},
+		{Stat: "strength", MinValue: 12},
+		{Stat: "dexterity", MinValue: 12},
+	}
+	placed := 0
+	for _, i := range rng.Perm(len(locSlice)) {
+		if placed == 2 {
+			break
+		}
+		a := locSlice[i]
+		if len(a.Connections) < 2 {
+			continue
+		}
+		b := byID[a.Connections[rng.Intn(len(a.Connections))]]
+		if len(b.Connections) < 2 || a.Locks[b.ID] != nil {
+			continue
+		}
+
+		lock := locks[rng.Intn(len(locks))]
+		if a.Locks == nil {
+			a.Locks = make(map[string]*Lock)
+		}
+		if b.Locks == nil {
+			b.Locks = make(map[string]*Lock)
+		}
+		a.Locks[b.ID] = &lock
+		b.Locks[a.ID] = &lock
+		placed++
+	}
+
+	// One passage becomes one-way, as long as that does not cut any
+	// location off from somewhere it could reach before
+	reach := reachablePairs(locSlice, byID)
+	for _, i := range rng.Perm(len(locSlice)) {
+		a := locSlice[i]
+		for _, bID := range a.Connections {
+			b := byID[bID]
+			if a.Locks[bID] != nil {
+				continue
+			}
+
+			b.Connections = remove(b.Connections, a.ID)
+			if reachablePairs(locSlice, byID) == reach {
+				return
+			}
+			b.Connections = append(b.Connections, a.ID)
+		}
+	}
+}
+
+// reachablePairs counts the (from, to) pairs where to can be reached from
+// from by following connections.
+func reachablePairs(locSlice []*Location, byID map[string]*Location) int {
+	total := 0
+	for _, start := range locSlice {
+		seen := map[string]bool{start.ID: true}
+		queue := []string{start.ID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, next := range byID[id].Connections {
+				if !seen[next] {
+					seen[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		total += len(seen)
+	}
+	return total
+}
+
+func remove(slice []string, item string) []string {
+	result := make([]string, 0, len(slice))
+	for _, s := range slice {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckLock(t *testing.T) {
+	dest := &Location{Name: "Vault"}
+	tests := []struct {
+		name    string
+		lock    *Lock
+		player  *Player
+		wantErr bool
+	}{
+		{"no lock", nil, &Player{}, false},
+		{"key held", &Lock{KeyItem: "Iron Key"}, &Player{Inventory: []*Item{NewItem("Iron Key")}}, false},
+		{"key missing", &Lock{KeyItem: "Iron Key"}, &Player{Inventory: []*Item{NewItem("Rope")}}, true},
+		{"strong enough", &Lock{Stat: "strength", MinValue: 12}, &Player{Strength: 12}, false},
+		{"too weak", &Lock{Stat: "strength", MinValue: 12}, &Player{Strength: 11}, true},
+		{"weakened by poison", &Lock{Stat: "strength", MinValue: 12}, &Player{Strength: 12, Effects: []*StatusEffect{{Kind: EffectPoison, Stacks: 1}}}, true},
+		{"too clumsy", &Lock{Stat: "dexterity", MinValue: 12}, &Player{Dexterity: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkLock(tt.player, tt.lock, dest); (err != nil) != tt.wantErr {
+				t.Errorf("checkLock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTickHazards(t *testing.T) {
+	tests := []struct {
+		name        string
+		health      int
+		wantHealth  int
+		wantDefeats int
+	}{
+		{"survives", 10, 8, 0},
+		{"killed", 2, 0, 1},
+		{"already defeated", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			p := addTestPlayer(t, g, "a")
+
+			g.Mu.Lock()
+			defer g.Mu.Unlock()
+			g.Locations[p.CurrentLocation].Hazard = &Hazard{Kind: "biting cold", Damage: 2}
+			p.Health = tt.health
+
+			events := g.tickHazards(time.Now())
+			if p.Health != tt.wantHealth {
+				t.Errorf("health = %d, want %d", p.Health, tt.wantHealth)
+			}
+			if got := countEvents(events, EventPlayerLeft); got != tt.wantDefeats {
+				t.Errorf("defeats = %d, want %d", got, tt.wantDefeats)
+			}
+		})
+	}
+}
+
+func TestSafeZoneBlocksAttacks(t *testing.T) {
+	g := newTestGame(t, Options{})
+	a := addTestPlayer(t, g, "a")
+	addTestPlayer(t, g, "b")
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	g.Locations[a.CurrentLocation].SafeZone = true
+	if _, err := g.attackPlayer("a", "b", time.Now()); err == nil {
+		t.Error("attack in a safe zone succeeded")
+	}
+}
+
+func TestGeneratedFeatures(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		locations := GenerateWorld(DefaultLocationCount, GraphOptions{Seed: seed, Topology: TopologyRandom})
+
+		safe := 0
+		for _, loc := range locations {
+			if loc.SafeZone {
+				safe++
+			}
+			for to, lock := range loc.Locks {
+				if back := locations[to].Locks[loc.ID]; back != lock {
+					t.Errorf("seed %d: lock %s -> %s only works one way", seed, loc.ID, to)
+				}
+			}
+		}
+		if safe != 1 {
+			t.Errorf("seed %d: %d safe zones, want 1", seed, safe)
+		}
+	}
+}
+
+func TestCrowdingPenalty(t *testing.T) {
+	tests := []struct {
+		capacity  int
+		occupants int
+		want      int
+	}{
+		{0, 10, 0},
+		{8, 2, 0},
+		{8, 6, 5},
+		{8, 8, 10},
+	}
+
+	for _, tt := range tests {
+		g := newTestGame(t, Options{})
+		for i := range tt.occupants {
+			addTestPlayer(t, g, string(rune('a'+i)))
+		}
+		loc := g.Locations[firstLocation(g)]
+		loc.Capacity = tt.capacity
+
+		g.Mu.RLock()
+		got := g.crowdingPenalty(loc)
+		g.Mu.RUnlock()
+		if got != tt.want {
+			t.Errorf("capacity %d with %d players: penalty = %d, want %d", tt.capacity, tt.occupants, got, tt.want)
+		}
+	}
+}
+
+func TestCapacityBlocksMoves(t *testing.T) {
+	g := newTestGame(t, Options{})
+	mover := addTestPlayer(t, g, "mover")
+	blocker := addTestPlayer(t, g, "blocker")
+	away := openNeighbor(t, g)
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	g.Locations[away].Capacity = 1
+	blocker.CurrentLocation = away
+
+	if err := g.checkMove(mover, away); err == nil {
+		t.Error("moved into a full location")
+	}
+
+	// Defeated players take up no room
+	blocker.Health = 0
+	if err := g.checkMove(mover, away); err != nil {
+		t.Errorf("checkMove() error = %v", err)
+	}
+}
@@ -3,30 +3,204 @@ package game
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Percent chance that a landed attack also makes the target bleed
+const bleedChance = 20
+
 type Game struct {
 	ID        string
+	OwnerID   string // the first player to join; may moderate the game
+	Mode      Mode
 	Locations map[string]*Location
 	Players   map[string]*Player
+	Seed      int64 // world generation seed
+
+	Name       string
+	Tags       []string
+	MaxPlayers int // 0 means no limit
+	CreatedAt  time.Time
+	Topology   Topology
+	Ruleset    Ruleset
+	Visibility Visibility
+	Join       JoinPolicy
+	joinSecret string             // password, for JoinPassword
+	invites    map[string]*Invite // by code, for JoinInvite
+
+	TurnTimeout time.Duration
+	turns       turnState
+
+	TickWindow time.Duration
+	ticks      tickState
+
+	FriendlyFire bool
+	Teams        []string
+	TeamScores   map[string]int
+	ScoreLimit   int    // team score that wins the game; 0 means no limit
+	Winner       string // winning team, once decided
+	parties      map[string]*Party
+	trades       map[string]*Trade
+
+	Quests []*Quest
+	clock  worldClock
 
 	clientPlayers map[chan Event]string
+	spectators    map[chan Event]spectator
+	recentEvents  []eventRecord // guarded by ClientsMu, for checking reports
+
+	eventSeq atomic.Uint64
+	chatLog  []chatEntry
+
+	Filter   *ChatFilter
+	auditLog []AuditEntry
+	auditSeq int
+	bans     map[string]time.Time // ban key (see banKeys) -> ban expiry
 
 	Mu        sync.RWMutex
 	ClientsMu sync.Mutex
+
+	onUpdate func()
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Options configures a new game. The zero value is a real-time game.
+type Options struct {
+	Name          string // defaults to the game ID
+	Tags          []string
+	MaxPlayers    int
+	LocationCount int // defaults to DefaultLocationCount
+	Topology      Topology
+	Names         NamePool // location name words; nil uses DefaultNames
+	Ruleset       Ruleset  // zero value uses DefaultRuleset
+	Visibility    Visibility
+
+	// At most one of Password and InviteCode may be set; joining then
+	// requires it. InviteCode becomes the game's first invite, with no
+	// expiry or use limit. Private games always require an invite.
+	Password   string
+	InviteCode string
+
+	Mode        Mode
+	TurnTimeout time.Duration
+	TickWindow  time.Duration
+	ChatFilter  *ChatFilter
+
+	FriendlyFire bool
+	Teams        []string // team names; empty means no teams
+	ScoreLimit   int
+
+	// Quests available in this world. Quests naming locations the world
+	// does not have are left out. Nil uses DefaultQuests.
+	Quests []*Quest
+
+	// Seed makes world generation repeatable; 0 picks a random seed.
+	Seed int64
+
+	// OnUpdate, if set, is called whenever the game's lobby summary (player
+	// count or state) may have changed. It must not block.
+	OnUpdate func()
 }
 
-func NewGame(id string) *Game {
-	return &Game{
+func NewGame(id string, opts Options) *Game {
+	if opts.Mode == "" {
+		opts.Mode = ModeRealTime
+	}
+	if opts.TurnTimeout <= 0 {
+		opts.TurnTimeout = DefaultTurnTimeout
+	}
+	if opts.TickWindow <= 0 {
+		opts.TickWindow = DefaultTickWindow
+	}
+
+	if opts.LocationCount <= 0 {
+		opts.LocationCount = DefaultLocationCount
+	}
+	if opts.Topology == "" {
+		opts.Topology = TopologyRandom
+	}
+	if opts.Ruleset.Name == "" {
+		opts.Ruleset = Rulesets[DefaultRuleset]
+	}
+	if opts.Ruleset.FriendlyFire {
+		opts.FriendlyFire = true
+	}
+	if opts.Visibility == "" {
+		opts.Visibility = VisibilityPublic
+	}
+
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	locations := GenerateWorld(opts.LocationCount, GraphOptions{
+		Seed:        opts.Seed,
+		Topology:    opts.Topology,
+		Names:       opts.Names,
+		NoSafeZones: !opts.Ruleset.SafeZones,
+		NoHazards:   !opts.Ruleset.Hazards,
+	})
+	if opts.Quests == nil {
+		opts.Quests = DefaultQuests(locations)
+	}
+
+	if opts.Name == "" {
+		opts.Name = id
+	}
+
+	g := &Game{
 		ID:            id,
-		Locations:     GenerateGraph(10),
+		Name:          opts.Name,
+		Tags:          opts.Tags,
+		MaxPlayers:    opts.MaxPlayers,
+		CreatedAt:     time.Now(),
+		Topology:      opts.Topology,
+		Ruleset:       opts.Ruleset,
+		Visibility:    opts.Visibility,
+		Mode:          opts.Mode,
+		Seed:          opts.Seed,
+		TurnTimeout:   opts.TurnTimeout,
+		TickWindow:    opts.TickWindow,
+		ticks:         tickState{pending: make(map[string]*Command), resolveAt: time.Now().Add(opts.TickWindow)},
+		Locations:     locations,
+		Quests:        worldQuests(opts.Quests, locations),
+		clock:         newWorldClock(time.Now()),
 		Players:       make(map[string]*Player),
+		Filter:        opts.ChatFilter,
+		FriendlyFire:  opts.FriendlyFire,
+		Teams:         opts.Teams,
+		TeamScores:    make(map[string]int),
+		ScoreLimit:    opts.ScoreLimit,
+		parties:       make(map[string]*Party),
+		trades:        make(map[string]*Trade),
+		bans:          make(map[string]time.Time),
 		clientPlayers: make(map[chan Event]string),
+		spectators:    make(map[chan Event]spectator),
 		Mu:            sync.RWMutex{},
 		ClientsMu:     sync.Mutex{},
+		onUpdate:      opts.OnUpdate,
+		done:          make(chan struct{}),
 	}
+
+	g.invites = make(map[string]*Invite)
+	switch {
+	case opts.Password != "":
+		g.Join, g.joinSecret = JoinPassword, opts.Password
+	case opts.InviteCode != "" || opts.Visibility == VisibilityPrivate:
+		g.Join = JoinInvite
+		if opts.InviteCode != "" {
+			g.invites[opts.InviteCode] = &Invite{Code: opts.InviteCode, CreatedAt: g.CreatedAt}
+		}
+	default:
+		g.Join = JoinOpen
+	}
+
+	go g.run()
+	return g
 }
 
 func (g *Game) AddClient(ch chan Event, playerID string) {
@@ -38,8 +212,24 @@ func (g *Game) AddClient(ch chan Event, playerID string) {
 func (g *Game) RemoveClient(ch chan Event) {
 	g.ClientsMu.Lock()
 	defer g.ClientsMu.Unlock()
-	delete(g.clientPlayers, ch)
-	close(ch)
+	// BroadcastEvent drops and closes channels that fall behind, so only
+	// close channels that are still registered
+	if _, ok := g.clientPlayers[ch]; ok {
+		delete(g.clientPlayers, ch)
+		close(ch)
+	}
+	if _, ok := g.spectators[ch]; ok {
+		delete(g.spectators, ch)
+		close(ch)
+	}
+}
+
+// ClientCount returns how many players and spectators are streaming the
+// game's events.
+func (g *Game) ClientCount() int {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+	return len(g.clientPlayers) + len(g.spectators)
 }
 
 func (g *Game) shouldPlayerSeeEvent(playerID string, event Event) bool {
@@ -55,7 +245,32 @@ func (g *Game) shouldPlayerSeeEvent(playerID string, event Event) bool {
 		return false
 	}
 
-	return event.Location == player.CurrentLocation
+	return canSee(event, playerID, player.CurrentLocation)
+}
+
+// canSee decides whether a player standing at location receives event.
+// Events addressed to specific recipients go only to them and concealed
+// events only to the player who caused them; otherwise global events go to
+// everyone and the rest to players at the event's location or any of its
+// extra locations.
+func canSee(event Event, playerID, location string) bool {
+	if len(event.Recipients) > 0 {
+		return contains(event.Recipients, playerID)
+	}
+	if event.Concealed {
+		return event.PlayerID == playerID
+	}
+	if event.Global {
+		return true
+	}
+	return event.Location == location || contains(event.Locations, location)
+}
+
+// CanSeePlayer reports whether the player observerID can see other, who is
+// assumed to share their location. Hidden players are seen only by
+// themselves.
+func CanSeePlayer(observerID string, other *Player) bool {
+	return other.ID == observerID || !other.Hidden
 }
 
 func (g *Game) GetPlayer(id string) *Player {
@@ -75,9 +290,6 @@ func (g *Game) GetRandomLocation() *Location {
 }
 
 func (g *Game) MovePlayer(playerID, locationID string) error {
-	var departureEvent, arrivalEvent Event
-	var oldLocation, newLocation string
-
 	g.Mu.Lock()
 	player := g.Players[playerID]
 	if player == nil {
@@ -85,88 +297,209 @@ func (g *Game) MovePlayer(playerID, locationID string) error {
 		return fmt.Errorf("player not found")
 	}
 
+	now := time.Now()
+	if err := g.beginAction(player, ActionMove, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.movePlayer(playerID, locationID, now)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionMove, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// movePlayer validates and resolves a move, returning the events it
+// produced. Engaged opponents get attacks of opportunity first; if those
+// defeat the player the move fails but the attack events are still returned.
+// The caller must hold g.Mu.
+func (g *Game) movePlayer(playerID, locationID string, now time.Time) ([]Event, error) {
+	player := g.Players[playerID]
+	if player == nil {
+		return nil, fmt.Errorf("player not found")
+	}
+
+	if err := g.checkMove(player, locationID); err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if locationID != player.CurrentLocation {
+		events = g.opportunityAttacks(player, now)
+		if player.Health <= 0 {
+			return events, fmt.Errorf("player was defeated while disengaging")
+		}
+	}
+
+	// Walking off openly gives a hidden player away; see sneak
+	if player.Hidden {
+		events = append(events, g.reveal(player, nil)...)
+	}
+
+	return append(events, g.relocate(player, locationID)...), nil
+}
+
+// checkMove reports why player cannot move to locationID, if anything. The
+// caller must hold g.Mu.
+func (g *Game) checkMove(player *Player, locationID string) error {
+	if player.Incapacitated() {
+		return fmt.Errorf("player is stunned")
+	}
+
 	location := g.Locations[locationID]
 	if location == nil {
-		g.Mu.Unlock()
 		return fmt.Errorf("location not found")
 	}
 
-	currentLoc := g.Locations[player.CurrentLocation]
-	connected := false
-	for _, connID := range currentLoc.Connections {
-		if connID == locationID {
-			connected = true
-			break
-		}
+	if player.CurrentLocation == locationID {
+		return nil
 	}
 
-	if !connected && player.CurrentLocation != locationID {
-		g.Mu.Unlock()
+	currentLoc := g.Locations[player.CurrentLocation]
+	if !contains(currentLoc.Connections, locationID) {
+		if contains(location.Connections, currentLoc.ID) {
+			return fmt.Errorf("the passage from %s to %s is one-way", location.Name, currentLoc.Name)
+		}
 		return fmt.Errorf("location not connected")
 	}
 
-	oldLocation = player.CurrentLocation
-	newLocation = locationID
-	player.CurrentLocation = locationID
+	if err := checkLock(player, currentLoc.Locks[locationID], location); err != nil {
+		return err
+	}
 
-	departureEvent = Event{
-		Type:     EventPlayerMoved,
-		PlayerID: playerID,
-		Location: oldLocation,
-		Message:  fmt.Sprintf("%s left the area", player.Name),
+	if location.Capacity > 0 && g.occupancy(locationID) >= location.Capacity {
+		return fmt.Errorf("%s is full (%d/%d players)", location.Name, g.occupancy(locationID), location.Capacity)
 	}
 
-	arrivalEvent = Event{
-		Type:     EventPlayerMoved,
-		PlayerID: playerID,
-		Location: newLocation,
-		Message:  fmt.Sprintf("%s arrived", player.Name),
+	return nil
+}
+
+// relocate moves player to locationID, ending any engagements if they leave
+// their current location, and returns the departure and arrival events.
+// A hidden player's movement is concealed, and arriving anywhere triggers
+// perception checks. The caller must hold g.Mu.
+func (g *Game) relocate(player *Player, locationID string) []Event {
+	oldLocation := player.CurrentLocation
+	newLocation := locationID
+	player.CurrentLocation = locationID
+	if oldLocation != newLocation {
+		g.disengage(player)
 	}
 
-	g.Mu.Unlock()
+	departureEvent := Event{
+		Type:      EventPlayerMoved,
+		PlayerID:  player.ID,
+		Location:  oldLocation,
+		Message:   fmt.Sprintf("%s left the area", player.Name),
+		Concealed: player.Hidden,
+	}
 
-	g.BroadcastEvent(departureEvent)
-	g.BroadcastEvent(arrivalEvent)
+	arrivalEvent := Event{
+		Type:      EventPlayerMoved,
+		PlayerID:  player.ID,
+		Location:  newLocation,
+		Message:   fmt.Sprintf("%s arrived", player.Name),
+		Concealed: player.Hidden,
+	}
 
-	return nil
+	events := []Event{departureEvent, arrivalEvent}
+	if oldLocation != newLocation {
+		events = append(events, g.questArrival(player, g.Locations[newLocation])...)
+		events = append(events, g.perceptionChecks(player)...)
+	}
+	return events
 }
 
 func (g *Game) AttackPlayer(attackerID, targetID string) error {
-	var attackEvent, defeatEvent Event
-	var shouldBroadcastDefeat bool
-
 	g.Mu.Lock()
+	attacker := g.Players[attackerID]
+	if attacker == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
 
+	now := time.Now()
+	if err := g.beginAction(attacker, ActionAttack, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.attackPlayer(attackerID, targetID, now)
+	if err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+	events = append(events, g.finishAction(attacker, ActionAttack, now)...)
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return nil
+}
+
+// attackPlayer validates and resolves an attack, returning the events it
+// produced. The caller must hold g.Mu.
+func (g *Game) attackPlayer(attackerID, targetID string, now time.Time) ([]Event, error) {
 	attacker := g.Players[attackerID]
 	target := g.Players[targetID]
 
 	if attacker == nil || target == nil {
-		g.Mu.Unlock()
-		return fmt.Errorf("player not found")
+		return nil, fmt.Errorf("player not found")
+	}
+	if attacker.Health <= 0 {
+		return nil, fmt.Errorf("player is defeated")
+	}
+	if target.Health <= 0 {
+		return nil, fmt.Errorf("%s is already defeated", target.Name)
 	}
 
-	if attacker.CurrentLocation != target.CurrentLocation {
-		g.Mu.Unlock()
-		return fmt.Errorf("players not in same location")
+	// A hidden target is indistinguishable from an absent one
+	if attacker.CurrentLocation != target.CurrentLocation || !CanSeePlayer(attackerID, target) {
+		return nil, fmt.Errorf("players not in same location")
 	}
 
+	if loc := g.Locations[attacker.CurrentLocation]; loc != nil && loc.SafeZone {
+		return nil, fmt.Errorf("%s is a safe zone", loc.Name)
+	}
+
+	if attacker.Incapacitated() {
+		return nil, fmt.Errorf("player is stunned")
+	}
+
+	if !g.FriendlyFire && attacker.ID != target.ID && allies(attacker, target) {
+		return nil, fmt.Errorf("friendly fire is disabled")
+	}
+
+	// Striking from hiding is an ambush, and gives the attacker away
+	ambush := attacker.Hidden
+	attacker.Hidden = false
+
+	engage(attacker, target, now)
+
 	// Calculate dodge chance based on target's dexterity
 	// Dexterity 3-18: gives 0-30% dodge chance (2% per point)
-	dodgeChance := (target.Dexterity - 3) * 2
+	// Crowded rooms and fog make dodging harder, and an incapacitated or
+	// ambushed target cannot dodge at all
+	dodgeChance := (target.EffectiveDexterity()-3)*2 - g.crowdingPenalty(g.Locations[target.CurrentLocation]) - g.weatherDodgePenalty()
+	if target.Incapacitated() || ambush {
+		dodgeChance = 0
+	}
 	dodgeRoll := rand.Intn(100)
 
 	if dodgeRoll < dodgeChance {
 		// Target dodged the attack
-		attackEvent = Event{
+		return []Event{{
 			Type:     EventPlayerAttack,
 			PlayerID: attackerID,
 			TargetID: targetID,
 			Location: attacker.CurrentLocation,
 			Message:  fmt.Sprintf("%s attacked %s, but they dodged!", attacker.Name, target.Name),
-		}
-		g.Mu.Unlock()
-		g.BroadcastEvent(attackEvent)
-		return nil
+		}}, nil
 	}
 
 	// Calculate base damage
@@ -174,48 +507,113 @@ func (g *Game) AttackPlayer(attackerID, targetID string) error {
 
 	// Strength modifier: +/- 20% based on strength difference from average (10.5)
 	// Attacker's strength increases damage, target's strength reduces it
-	attackerMod := float64(attacker.Strength-10) * 0.5 // -3.5 to +3.5
-	targetMod := float64(target.Strength-10) * 0.25    // -1.75 to +1.75 (defense is weaker)
+	attackerMod := float64(attacker.EffectiveStrength()-10) * 0.5 // -3.5 to +3.5
+	targetMod := float64(target.EffectiveStrength()-10) * 0.25    // -1.75 to +1.75 (defense is weaker)
 
-	damage := baseDamage + int(attackerMod-targetMod)
+	damage := baseDamage + int(attackerMod-targetMod) + attacker.DamageBonus()
+	if ambush {
+		damage += AmbushBonus
+	}
 	if damage < 1 {
 		damage = 1 // Minimum 1 damage
 	}
 
 	target.Health -= damage
 
-	attackEvent = Event{
+	verb := "attacked"
+	if ambush {
+		verb = "ambushed"
+	}
+	events := []Event{{
 		Type:     EventPlayerAttack,
 		PlayerID: attackerID,
 		TargetID: targetID,
 		Location: attacker.CurrentLocation,
-		Message:  fmt.Sprintf("%s attacked %s for %d damage", attacker.Name, target.Name, damage),
-	}
+		Message:  fmt.Sprintf("%s %s %s for %d damage", attacker.Name, verb, target.Name, damage),
+	}}
 
 	if target.Health <= 0 {
-		target.Health = 0
-		shouldBroadcastDefeat = true
-		defeatEvent = Event{
-			Type:     EventPlayerLeft,
-			PlayerID: targetID,
-			Location: attacker.CurrentLocation,
-			Message:  fmt.Sprintf("%s has been defeated!", target.Name),
+		events = append(events, g.defeat(target, attackerID)...)
+	} else if rand.Intn(100) < bleedChance {
+		// Solid hits can open a wound
+		if bleedEvent, err := g.applyEffect(target, EffectBleed, attackerID, now); err == nil {
+			events = append(events, bleedEvent)
 		}
 	}
 
-	g.Mu.Unlock()
+	return events, nil
+}
 
-	g.BroadcastEvent(attackEvent)
-	if shouldBroadcastDefeat {
-		g.BroadcastEvent(defeatEvent)
+// defeat handles a player's health reaching zero: it clears their effects,
+// engagements and trades, credits killerID's team, and returns the resulting
+// events. killerID may be empty. A player is only ever defeated once; later
+// calls return nothing. The caller must hold g.Mu.
+func (g *Game) defeat(victim *Player, killerID string) []Event {
+	if victim.defeated {
+		return nil
 	}
-
-	return nil
+	victim.defeated = true
+	victim.Health = 0
+	victim.Effects = nil
+	victim.Hidden = false
+	g.disengage(victim)
+
+	events := []Event{{
+		Type:     EventPlayerLeft,
+		PlayerID: victim.ID,
+		TargetID: killerID,
+		Location: victim.CurrentLocation,
+		Message:  fmt.Sprintf("%s has been defeated!", victim.Name),
+	}}
+	events = append(events, g.cancelTrades(victim, victim.Name+" was defeated")...)
+
+	if killer := g.Players[killerID]; killer != nil {
+		events = append(events, g.scoreDefeat(killer, victim)...)
+		events = append(events, g.questDefeat(killer, false)...)
+	}
+	return events
 }
 
-func (g *Game) AddPlayer(player *Player) {
+// AddPlayer adds player to the game. team requests a team in team games and
+// may be empty to be assigned to the smallest team. code is the password or
+// invite code, if the game requires one.
+func (g *Game) AddPlayer(player *Player, team, code string) error {
+	var joinEvents []Event
+
 	g.Mu.Lock()
+	if g.MaxPlayers > 0 && len(g.Players) >= g.MaxPlayers {
+		g.Mu.Unlock()
+		return ErrGameFull
+	}
+	if g.playerByAccount(player.AccountID) != nil {
+		g.Mu.Unlock()
+		return ErrAccountJoined
+	}
+	if g.banned(player, time.Now()) {
+		g.Mu.Unlock()
+		return ErrBanned
+	}
+	// Team assignment is checked first so a rejected join never uses up
+	// an invite
+	if err := g.assignTeam(player, team); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+	if err := g.admit(player.ID, code, time.Now()); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
 	g.Players[player.ID] = player
+	// A bot never owns a game, so it never moderates one
+	if g.OwnerID == "" && !player.Bot {
+		g.OwnerID = player.ID
+	}
+	joinEvents = g.startQuests(player)
+	// The first player to join a turn-based game starts round one; anyone
+	// joining later waits for the next initiative roll.
+	if g.Mode == ModeTurnBased && g.turns.activePlayerID() == "" {
+		joinEvents = append(joinEvents, g.startTurn(time.Now())...)
+	}
 	g.Mu.Unlock()
 
 	g.BroadcastEvent(Event{
@@ -224,10 +622,87 @@ func (g *Game) AddPlayer(player *Player) {
 		Message:  player.Name + " joined the game",
 		Global:   true,
 	})
+	g.broadcastEvents(joinEvents)
+	return nil
+}
+
+// PlayerByAccount returns the account's character in this game, if it has
+// one.
+func (g *Game) PlayerByAccount(accountID string) *Player {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	return g.playerByAccount(accountID)
+}
+
+// playerByAccount is PlayerByAccount for callers that hold g.Mu.
+func (g *Game) playerByAccount(accountID string) *Player {
+	if accountID == "" {
+		return nil
+	}
+	for _, p := range g.Players {
+		if p.AccountID == accountID {
+			return p
+		}
+	}
+	return nil
+}
+
+// State describes where a game is in its lifecycle.
+type State string
+
+const (
+	StateWaiting  State = "waiting"  // nobody has joined yet
+	StateActive   State = "active"   // players are in the game
+	StateFinished State = "finished" // a team has won
+)
+
+// State returns the game's lifecycle state. The caller must hold g.Mu.
+func (g *Game) State() State {
+	switch {
+	case g.Winner != "":
+		return StateFinished
+	case len(g.Players) == 0:
+		return StateWaiting
+	default:
+		return StateActive
+	}
+}
+
+// OpenSlots reports whether another player could join. The caller must hold
+// g.Mu.
+func (g *Game) OpenSlots() bool {
+	if g.State() == StateFinished {
+		return false
+	}
+	return g.MaxPlayers == 0 || len(g.Players) < g.MaxPlayers
+}
+
+// summaryEvents are the events that change what the lobby shows about a
+// game.
+var summaryEvents = map[EventType]bool{
+	EventPlayerJoined: true,
+	EventPlayerKicked: true,
+	EventTeamWon:      true,
+}
+
+func (g *Game) broadcastEvents(events []Event) {
+	for _, event := range events {
+		g.BroadcastEvent(event)
+	}
+}
+
+// nextEventID returns a game-unique, increasing event ID.
+func (g *Game) nextEventID() string {
+	return strconv.FormatUint(g.eventSeq.Add(1), 10)
 }
 
 func (g *Game) BroadcastEvent(event Event) {
-	event.Timestamp = time.Now()
+	if event.ID == "" {
+		event.ID = g.nextEventID()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	playerLocations := make(map[string]string)
 	if !event.Global {
@@ -238,13 +713,16 @@ func (g *Game) BroadcastEvent(event Event) {
 		g.Mu.RUnlock()
 	}
 
+	if g.onUpdate != nil && summaryEvents[event.Type] {
+		g.onUpdate()
+	}
+
 	g.ClientsMu.Lock()
 	defer g.ClientsMu.Unlock()
 
+	g.recordEvent(event)
 	for clientChan, playerID := range g.clientPlayers {
-		shouldSee := event.Global || playerLocations[playerID] == event.Location
-
-		if shouldSee {
+		if canSee(event, playerID, playerLocations[playerID]) {
 			select {
 			case clientChan <- event:
 			default:
@@ -253,4 +731,14 @@ func (g *Game) BroadcastEvent(event Event) {
 			}
 		}
 	}
+	for clientChan, sp := range g.spectators {
+		if sp.canSee(event) {
+			select {
+			case clientChan <- event:
+			default:
+				close(clientChan)
+				delete(g.spectators, clientChan)
+			}
+		}
+	}
 }
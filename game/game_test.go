@@ -0,0 +1,145 @@
+package game
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+// testRuleset has no safe zones or hazards, so nothing but the test touches
+// a player's health.
+var testRuleset = Ruleset{Name: "test", StartingHealth: 100}
+
+// newTestGame creates a game with a fixed seed. Its background loop is
+// stopped straight away, so tests drive ticks themselves.
+func newTestGame(t *testing.T, opts Options) *Game {
+	t.Helper()
+	if opts.Seed == 0 {
+		opts.Seed = 1
+	}
+	if opts.Ruleset.Name == "" {
+		opts.Ruleset = testRuleset
+	}
+	g := NewGame("test", opts)
+	g.Stop()
+	return g
+}
+
+// firstLocation is the lowest location ID, so tests can put players together.
+func firstLocation(g *Game) string {
+	ids := make([]string, 0, len(g.Locations))
+	for id := range g.Locations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
+
+// addTestPlayer joins a player with average stats at the first location.
+func addTestPlayer(t *testing.T, g *Game, id string) *Player {
+	t.Helper()
+	p := &Player{
+		ID:              id,
+		Name:            id,
+		CurrentLocation: firstLocation(g),
+		Health:          g.Ruleset.StartingHealth,
+		Strength:        10,
+		Dexterity:       3,
+	}
+	if err := g.AddPlayer(p, "", ""); err != nil {
+		t.Fatalf("AddPlayer(%s): %v", id, err)
+	}
+	return p
+}
+
+// countEvents counts the events of type typ, including those batched
+// inside other events.
+func countEvents(events []Event, typ EventType) int {
+	n := 0
+	for _, e := range events {
+		if e.Type == typ {
+			n++
+		}
+		n += countEvents(e.Events, typ)
+	}
+	return n
+}
+
+// openNeighbor is a location reachable from the first location through an
+// unlocked passage.
+func openNeighbor(t *testing.T, g *Game) string {
+	t.Helper()
+	from := g.Locations[firstLocation(g)]
+	for _, id := range from.Connections {
+		if from.Locks[id] == nil && contains(g.Locations[id].Connections, from.ID) {
+			return id
+		}
+	}
+	t.Fatal("first location has no open two-way passage")
+	return ""
+}
+
+func TestAttackPlayerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		prepare func(g *Game, a, b *Player)
+		target  string
+	}{
+		{"unknown target", Options{}, func(*Game, *Player, *Player) {}, "nobody"},
+		{"attacker defeated", Options{}, func(_ *Game, a, _ *Player) { a.Health = 0 }, "b"},
+		{"target defeated", Options{}, func(_ *Game, _, b *Player) { b.Health = 0 }, "b"},
+		{"different location", Options{}, func(_ *Game, _, b *Player) { b.CurrentLocation = "elsewhere" }, "b"},
+		{"hidden target", Options{}, func(_ *Game, _, b *Player) { b.Hidden = true }, "b"},
+		{"friendly fire", Options{Teams: []string{"red", "blue"}}, func(_ *Game, a, b *Player) { b.Team = a.Team }, "b"},
+		{"stunned", Options{}, func(_ *Game, a, _ *Player) {
+			a.Effects = []*StatusEffect{{Kind: EffectStun, Stacks: 1, ExpiresAt: time.Now().Add(time.Minute)}}
+		}, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, tt.opts)
+			a := addTestPlayer(t, g, "a")
+			b := addTestPlayer(t, g, "b")
+
+			g.Mu.Lock()
+			defer g.Mu.Unlock()
+			tt.prepare(g, a, b)
+			health := b.Health
+
+			if _, err := g.attackPlayer("a", tt.target, time.Now()); err == nil {
+				t.Fatal("attackPlayer() succeeded, want an error")
+			}
+			if b.Health != health {
+				t.Errorf("a rejected attack changed the target's health")
+			}
+		})
+	}
+}
+
+func TestDefeatScoresOnce(t *testing.T) {
+	g := newTestGame(t, Options{Teams: []string{"red", "blue"}})
+	a := addTestPlayer(t, g, "a")
+	b := addTestPlayer(t, g, "b")
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	b.Health = 1
+	for b.Health > 0 {
+		if _, err := g.attackPlayer("a", "b", time.Now()); err != nil {
+			t.Fatalf("attackPlayer() error = %v", err)
+		}
+	}
+
+	// Neither another strike nor a second defeat scores again
+	if _, err := g.attackPlayer("a", "b", time.Now()); err == nil {
+		t.Error("attacking a defeated player succeeded")
+	}
+	if events := g.defeat(b, "a"); len(events) != 0 {
+		t.Errorf("second defeat returned %d events", len(events))
+	}
+	if score := g.TeamScores[a.Team]; score != 1 {
+		t.Errorf("team score = %d, want 1", score)
+	}
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"fmt"
 	"game-api/utils"
+	"math"
 	"math/rand"
 	"time"
 )
@@ -11,70 +12,162 @@ type Location struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
-	Connections []string `json:"connections"` // IDs of connected locations
+	Biome       Biome    `json:"biome"`
+	Connections []string `json:"connections"` // IDs of locations reachable from here
+
+	Capacity int              `json:"capacity,omitempty"`  // max players present; 0 means unlimited
+	SafeZone bool             `json:"safe_zone,omitempty"` // no attacks allowed
+	Hazard   *Hazard          `json:"hazard,omitempty"`
+	Locks    map[string]*Lock `json:"locks,omitempty"` // destination ID -> lock on that passage
 }
 
+// Topology is the overall shape of the passages between locations.
+type Topology string
+
+const (
+	TopologyRandom Topology = "random" // 1-3 random passages per location
+	TopologyRing   Topology = "ring"   // a loop with a few shortcuts across it
+	TopologyGrid   Topology = "grid"   // rows and columns of neighbors
+	TopologyTree   Topology = "tree"   // branching paths with no loops
+)
+
+// ParseTopology validates a topology name. The empty string selects random.
+func ParseTopology(s string) (Topology, error) {
+	switch Topology(s) {
+	case "", TopologyRandom:
+		return TopologyRandom, nil
+	case TopologyRing:
+		return TopologyRing, nil
+	case TopologyGrid:
+		return TopologyGrid, nil
+	case TopologyTree:
+		return TopologyTree, nil
+	}
+	return "", fmt.Errorf("unknown topology: %s", s)
+}
+
+// GraphOptions controls world generation.
+type GraphOptions struct {
+	Seed     int64    // the same seed always produces the same world
+	Names    NamePool // nil uses DefaultNames
+	Topology Topology // empty means random
+
+	NoSafeZones bool
+	NoHazards   bool
+}
+
+// GenerateGraph creates a world with a time-based seed.
 func GenerateGraph(numLocations int) map[string]*Location {
-	locations := make(map[string]*Location)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return GenerateWorld(numLocations, GraphOptions{Seed: time.Now().UnixNano()})
+}
 
-	locationNames := []string{
-		"Dark Forest", "Ancient Castle", "Misty Mountains", "Crystal Cave",
-		"Desert Ruins", "Frozen Lake", "Abandoned Mine", "Haunted Graveyard",
-		"Dragon's Lair", "Enchanted Garden", "Pirate Cove", "Volcano Peak",
+// GenerateWorld creates a connected set of locations with biomes, features,
+// unique names and descriptions. All randomness comes from opts.Seed.
+func GenerateWorld(numLocations int, opts GraphOptions) map[string]*Location {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	if opts.Names == nil {
+		opts.Names = DefaultNames
 	}
 
-	// Create locations
-	for i := 0; i < numLocations; i++ {
-		id := utils.GenerateID(8)
-		name := locationNames[i%len(locationNames)]
-		if i >= len(locationNames) {
-			name = fmt.Sprintf("%s %d", name, i/len(locationNames))
+	// Create locations, keeping creation order so generation is repeatable
+	locations := make(map[string]*Location)
+	locSlice := make([]*Location, 0, numLocations)
+	for len(locSlice) < numLocations {
+		id := utils.GenerateIDFrom(rng, 8)
+		if locations[id] != nil {
+			continue
 		}
 
-		locations[id] = &Location{
+		loc := &Location{
 			ID:          id,
-			Name:        name,
-			Description: fmt.Sprintf("A mysterious %s", name),
 			Connections: []string{},
 		}
+		locations[id] = loc
+		locSlice = append(locSlice, loc)
 	}
 
-	// Convert map to slice for easier indexing
-	locSlice := make([]*Location, 0, len(locations))
-	for _, loc := range locations {
-		locSlice = append(locSlice, loc)
+	connect(rng, locSlice, opts.Topology)
+
+	assignBiomes(rng, locSlice, locations)
+	addFeatures(rng, locSlice)
+	for _, loc := range locSlice {
+		if opts.NoSafeZones {
+			loc.SafeZone = false
+		}
+		if opts.NoHazards {
+			loc.Hazard = nil
+		}
+	}
+
+	names := newNameGenerator(rng, opts.Names)
+	for _, loc := range locSlice {
+		loc.Name = names.name(loc.Biome)
+	}
+	for _, loc := range locSlice {
+		loc.Description = describe(rng, loc, locations)
 	}
 
-	// Connect locations randomly (ensure at least one connection per location)
-	for i, loc := range locSlice {
-		numConnections := rng.Intn(3) + 1 // 1-3 connections
+	return locations
+}
 
-		for j := 0; j < numConnections; j++ {
-			targetIdx := rng.Intn(len(locSlice))
-			if targetIdx != i { // Don't connect to self
-				target := locSlice[targetIdx]
+// connect adds bidirectional passages between locations in the given shape.
+func connect(rng *rand.Rand, locSlice []*Location, topology Topology) {
+	link := func(a, b *Location) {
+		if a == b {
+			return
+		}
+		if !contains(a.Connections, b.ID) {
+			a.Connections = append(a.Connections, b.ID)
+		}
+		if !contains(b.Connections, a.ID) {
+			b.Connections = append(b.Connections, a.ID)
+		}
+	}
+	n := len(locSlice)
 
-				// Add bidirectional connection if not already connected
-				if !contains(loc.Connections, target.ID) {
-					loc.Connections = append(loc.Connections, target.ID)
-				}
-				if !contains(target.Connections, loc.ID) {
-					target.Connections = append(target.Connections, loc.ID)
-				}
+	switch topology {
+	case TopologyRing:
+		for i := range locSlice {
+			link(locSlice[i], locSlice[(i+1)%n])
+		}
+		for range n / 4 {
+			link(locSlice[rng.Intn(n)], locSlice[rng.Intn(n)])
+		}
+
+	case TopologyGrid:
+		width := int(math.Ceil(math.Sqrt(float64(n))))
+		for i := range locSlice {
+			if (i+1)%width != 0 && i+1 < n {
+				link(locSlice[i], locSlice[i+1])
+			}
+			if i+width < n {
+				link(locSlice[i], locSlice[i+width])
 			}
 		}
 
-		// Ensure at least one connection
-		if len(loc.Connections) == 0 && len(locSlice) > 1 {
-			targetIdx := (i + 1) % len(locSlice)
-			target := locSlice[targetIdx]
-			loc.Connections = append(loc.Connections, target.ID)
-			target.Connections = append(target.Connections, loc.ID)
+	case TopologyTree:
+		for i := 1; i < n; i++ {
+			link(locSlice[i], locSlice[rng.Intn(i)])
 		}
-	}
 
-	return locations
+	default:
+		// Connect locations randomly (ensure at least one connection per location)
+		for i, loc := range locSlice {
+			numConnections := rng.Intn(3) + 1 // 1-3 connections
+
+			for j := 0; j < numConnections; j++ {
+				targetIdx := rng.Intn(n)
+				if targetIdx != i { // Don't connect to self
+					link(loc, locSlice[targetIdx])
+				}
+			}
+
+			// Ensure at least one connection
+			if len(loc.Connections) == 0 && n > 1 {
+				link(loc, locSlice[(i+1)%n])
+			}
+		}
+	}
 }
 
 func contains(slice []string, item string) bool {
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"game-api/utils"
+)
+
+// MaxInvites bounds how many invites one game keeps. Once a game reaches
+// it, invites that can no longer be used are dropped to make room.
+const MaxInvites = 50
+
+// Invite lets players join a game that requires one. An invite stops
+// working once it expires, is revoked, or has been used MaxUses times.
+type Invite struct {
+	Code      string    `json:"code"`
+	CreatedBy string    `json:"created_by,omitempty"` // empty for the invite made with the game
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // zero means never
+	MaxUses   int       `json:"max_uses"`            // 0 means unlimited
+	Uses      int       `json:"uses"`
+	UsedBy    []string  `json:"used_by,omitempty"` // player IDs, in order of use
+	Revoked   bool      `json:"revoked,omitempty"`
+}
+
+// check reports why the invite cannot be used right now, if anything.
+func (inv *Invite) check(now time.Time) error {
+	switch {
+	case inv.Revoked:
+		return ErrInviteRevoked
+	case !inv.ExpiresAt.IsZero() && !now.Before(inv.ExpiresAt):
+		return ErrInviteExpired
+	case inv.MaxUses > 0 && inv.Uses >= inv.MaxUses:
+		return ErrInviteUsedUp
+	}
+	return nil
+}
+
+// CreateInvite issues a new invite. ttl of 0 never expires and maxUses of 0
+// is unlimited. Only the owner may create invites.
+func (g *Game) CreateInvite(actorID string, ttl time.Duration, maxUses int) (Invite, error) {
+	if ttl < 0 {
+		return Invite{}, fmt.Errorf("ttl must not be negative")
+	}
+	if maxUses < 0 {
+		return Invite{}, fmt.Errorf("max_uses must not be negative")
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+
+	if !g.canModerate(actorID) {
+		return Invite{}, fmt.Errorf("only the game owner can create invites")
+	}
+	now := time.Now()
+	if len(g.invites) >= MaxInvites {
+		g.pruneInvites(now)
+	}
+	if len(g.invites) >= MaxInvites {
+		return Invite{}, fmt.Errorf("this game already has %d usable invites", MaxInvites)
+	}
+
+	inv := &Invite{
+		Code:      utils.SecureID(10),
+		CreatedBy: actorID,
+		CreatedAt: now,
+		MaxUses:   maxUses,
+	}
+	if ttl > 0 {
+		inv.ExpiresAt = now.Add(ttl)
+	}
+	g.invites[inv.Code] = inv
+
+	g.audit(AuditEntry{
+		Kind:      AuditInviteCreated,
+		ActorID:   actorID,
+		Detail:    inv.Code,
+		Timestamp: now,
+	})
+	return *inv, nil
+}
+
+// pruneInvites drops expired, revoked and used-up invites. The caller must
+// hold g.Mu.
+func (g *Game) pruneInvites(now time.Time) {
+	for code, inv := range g.invites {
+		if inv.check(now) != nil {
+			delete(g.invites, code)
+		}
+	}
+}
+
+// Invites returns every invite the game keeps, oldest first. Only the
+// owner may list invites.
+func (g *Game) Invites(actorID string) ([]Invite, error) {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	if !g.canModerate(actorID) {
+		return nil, fmt.Errorf("only the game owner can list invites")
+	}
+
+	invites := make([]Invite, 0, len(g.invites))
+	for _, inv := range g.invites {
+		copied := *inv
+		copied.UsedBy = append([]string(nil), inv.UsedBy...)
+		invites = append(invites, copied)
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		if !invites[i].CreatedAt.Equal(invites[j].CreatedAt) {
+			return invites[i].CreatedAt.Before(invites[j].CreatedAt)
+		}
+		return invites[i].Code < invites[j].Code
+	})
+	return invites, nil
+}
+
+// RevokeInvite stops an invite from being used again. Players who already
+// joined with it are unaffected.
+func (g *Game) RevokeInvite(actorID, code string) error {
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+
+	if !g.canModerate(actorID) {
+		return fmt.Errorf("only the game owner can revoke invites")
+	}
+	inv := g.invites[code]
+	if inv == nil {
+		return fmt.Errorf("invite not found")
+	}
+
+	inv.Revoked = true
+	g.audit(AuditEntry{
+		Kind:      AuditInviteRevoked,
+		ActorID:   actorID,
+		Detail:    code,
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// CheckInvite reports whether code is a usable invite without using it.
+func (g *Game) CheckInvite(code string) error {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	inv := g.invites[code]
+	if inv == nil {
+		return ErrJoinCodeWrong
+	}
+	return inv.check(time.Now())
+}
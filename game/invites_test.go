@@ -0,0 +1,110 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newInviteGame creates an invite-only game whose owner joined with its
+// first invite, "first".
+func newInviteGame(t *testing.T) *Game {
+	t.Helper()
+	g := newTestGame(t, Options{InviteCode: "first"})
+	if err := joinWith(g, "owner", "first"); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func joinWith(g *Game, id, code string) error {
+	return g.AddPlayer(&Player{ID: id, Name: id, CurrentLocation: firstLocation(g), Health: 100}, "", code)
+}
+
+func TestCreateInvite(t *testing.T) {
+	g := newInviteGame(t)
+	if _, err := g.CreateInvite("owner", -time.Second, 0); err == nil {
+		t.Error("accepted a negative ttl")
+	}
+	if _, err := g.CreateInvite("owner", 0, -1); err == nil {
+		t.Error("accepted negative max uses")
+	}
+	if _, err := g.CreateInvite("guest", 0, 0); err == nil {
+		t.Error("a non-owner created an invite")
+	}
+
+	// The game's first invite counts towards the limit
+	for i := 1; i < MaxInvites; i++ {
+		if _, err := g.CreateInvite("owner", 0, 0); err != nil {
+			t.Fatalf("invite %d: %v", i, err)
+		}
+	}
+	if _, err := g.CreateInvite("owner", 0, 0); err == nil {
+		t.Error("created more than MaxInvites invites")
+	}
+
+	// Invites that can no longer be used make room for new ones
+	if err := g.RevokeInvite("owner", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.CreateInvite("owner", 0, 0); err != nil {
+		t.Fatalf("creating an invite after revoking one: %v", err)
+	}
+	if _, kept := g.invites["first"]; kept {
+		t.Error("revoked invite kept")
+	}
+}
+
+func TestInviteJoin(t *testing.T) {
+	g := newInviteGame(t)
+	once, _ := g.CreateInvite("owner", time.Hour, 1)
+	revoked, _ := g.CreateInvite("owner", 0, 0)
+	expired, _ := g.CreateInvite("owner", time.Hour, 0)
+	if err := g.RevokeInvite("owner", revoked.Code); err != nil {
+		t.Fatal(err)
+	}
+	g.invites[expired.Code].ExpiresAt = time.Now().Add(-time.Second)
+
+	if err := joinWith(g, "a", once.Code); err != nil {
+		t.Fatalf("joining with a valid invite: %v", err)
+	}
+
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"", ErrJoinCodeNeeded},
+		{"nope", ErrJoinCodeWrong},
+		{revoked.Code, ErrInviteRevoked},
+		{expired.Code, ErrInviteExpired},
+		{once.Code, ErrInviteUsedUp},
+	}
+	for _, tt := range tests {
+		if err := joinWith(g, "b", tt.code); !errors.Is(err, tt.want) {
+			t.Errorf("joining with %q = %v, want %v", tt.code, err, tt.want)
+		}
+	}
+
+	invites, err := g.Invites("owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, inv := range invites {
+		if inv.Code == once.Code && (inv.Uses != 1 || len(inv.UsedBy) != 1 || inv.UsedBy[0] != "a") {
+			t.Errorf("invite after use = %+v", inv)
+		}
+	}
+}
+
+func TestInviteOwnerOnly(t *testing.T) {
+	g := newInviteGame(t)
+	if _, err := g.Invites("guest"); err == nil {
+		t.Error("Invites allowed a non-owner")
+	}
+	if err := g.RevokeInvite("guest", "first"); err == nil {
+		t.Error("RevokeInvite allowed a non-owner")
+	}
+	if err := g.RevokeInvite("owner", "nope"); err == nil {
+		t.Error("revoked an unknown invite")
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"game-api/utils"
+)
+
+type Item struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// itemCatalog lists the items that can be handed out. Each handed-out copy
+// gets its own ID.
+var itemCatalog = []Item{
+	{Name: "Health Potion", Kind: "potion"},
+	{Name: "Antidote", Kind: "potion"},
+	{Name: "Iron Key", Kind: "key"},
+	{Name: "Torch", Kind: "tool"},
+	{Name: "Rope", Kind: "tool"},
+	{Name: "Silver Ring", Kind: "trinket"},
+	{Name: "Old Map", Kind: "trinket"},
+}
+
+// itemUse is what using a consumable does: the effect it applies, if any,
+// and the effects it cures.
+type itemUse struct {
+	applies  EffectKind
+	cleanses []EffectKind
+}
+
+// itemUses lists the items that can be used, by name. Using one consumes it.
+var itemUses = map[string]itemUse{
+	"Health Potion": {applies: EffectRegen, cleanses: []EffectKind{EffectBleed}},
+	"Antidote":      {cleanses: []EffectKind{EffectPoison}},
+}
+
+// NewItem creates a fresh copy of the named catalog item, or nil if there is
+// no such item.
+func NewItem(name string) *Item {
+	for _, template := range itemCatalog {
+		if template.Name == name {
+			item := template
+			item.ID = utils.GenerateID(10)
+			return &item
+		}
+	}
+	return nil
+}
+
+// StarterItems returns the random items a new player begins with.
+func StarterItems() []*Item {
+	items := make([]*Item, 0, 2)
+	for _, i := range rand.Perm(len(itemCatalog))[:2] {
+		items = append(items, NewItem(itemCatalog[i].Name))
+	}
+	return items
+}
+
+// findItem returns the index of itemID in the player's inventory, or -1.
+func (p *Player) findItem(itemID string) int {
+	for i, item := range p.Inventory {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasItemNamed reports whether the player carries an item with this name.
+func (p *Player) HasItemNamed(name string) bool {
+	for _, item := range p.Inventory {
+		if item.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// takeItem removes itemID from the player's inventory and returns it, or nil
+// if they do not hold it. The caller must hold g.Mu.
+func (p *Player) takeItem(itemID string) *Item {
+	i := p.findItem(itemID)
+	if i < 0 {
+		return nil
+	}
+	item := p.Inventory[i]
+	p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+	return item
+}
+
+// UseItem consumes an item from the player's inventory for its effect.
+func (g *Game) UseItem(playerID, itemID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := g.beginAction(player, ActionUse, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.useItem(player, itemID, now)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionUse, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// useItem validates and resolves using an item. The caller must hold g.Mu.
+func (g *Game) useItem(player *Player, itemID string, now time.Time) ([]Event, error) {
+	if player.Health <= 0 {
+		return nil, fmt.Errorf("player is defeated")
+	}
+	i := player.findItem(itemID)
+	if i < 0 {
+		return nil, fmt.Errorf("item not in inventory")
+	}
+	use, ok := itemUses[player.Inventory[i].Name]
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be used", player.Inventory[i].Name)
+	}
+
+	item := player.takeItem(itemID)
+	events := []Event{{
+		Type:      EventItemUsed,
+		PlayerID:  player.ID,
+		Location:  player.CurrentLocation,
+		Message:   fmt.Sprintf("%s uses a %s", player.Name, item.Name),
+		Concealed: player.Hidden,
+	}}
+	for _, kind := range use.cleanses {
+		if g.cleanseEffect(player, kind) {
+			events = append(events, Event{
+				Type:      EventEffectExpired,
+				PlayerID:  player.ID,
+				Location:  player.CurrentLocation,
+				Message:   fmt.Sprintf("%s is no longer affected by %s", player.Name, kind),
+				Concealed: player.Hidden,
+			})
+		}
+	}
+	if use.applies != "" {
+		event, err := g.applyEffect(player, use.applies, item.ID, now)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,57 @@
+package game
+
+import "time"
+
+// TickInterval is how often the game advances time-based state such as
+// the clock, status effects, hazards and turn timeouts.
+const TickInterval = time.Second
+
+func (g *Game) run() {
+	ticker := time.NewTicker(TickInterval)
+	defer ticker.Stop()
+
+	// Simultaneous games resolve on their own window rather than waiting
+	// for the next regular tick.
+	var resolve <-chan time.Time
+	if g.Mode == ModeSimultaneous {
+		resolveTicker := time.NewTicker(g.TickWindow)
+		defer resolveTicker.Stop()
+		resolve = resolveTicker.C
+	}
+
+	for {
+		select {
+		case now := <-ticker.C:
+			g.tick(now)
+		case now := <-resolve:
+			g.Mu.Lock()
+			events := g.resolveTick(now)
+			g.Mu.Unlock()
+			g.broadcastEvents(events)
+		case <-g.done:
+			return
+		}
+	}
+}
+
+func (g *Game) tick(now time.Time) {
+	g.Mu.Lock()
+	events := g.tickClock(now)
+	events = append(events, g.tickEffects(now)...)
+	events = append(events, g.tickHazards(now)...)
+	events = append(events, g.expireTrades(now)...)
+	events = append(events, g.expirePartyInvites(now)...)
+	if g.Mode == ModeTurnBased {
+		events = append(events, g.tickTurns(now)...)
+	}
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+}
+
+// Stop halts the game's background loop. It is safe to call more than once.
+func (g *Game) Stop() {
+	g.stopOnce.Do(func() {
+		close(g.done)
+	})
+}
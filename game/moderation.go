@@ -0,0 +1,439 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type FilterAction string
+
+const (
+	FilterBlock FilterAction = "block" // reject the message
+	FilterMask  FilterAction = "mask"  // replace the match with asterisks
+	FilterFlag  FilterAction = "flag"  // deliver unchanged but record it for review
+)
+
+// FilterRule matches Pattern, a case-insensitive regular expression, against
+// chat text.
+type FilterRule struct {
+	Pattern string       `json:"pattern"`
+	Action  FilterAction `json:"action"`
+}
+
+// ChatFilter runs chat text through its rules in order. A block stops the
+// pipeline; masks apply to the text seen by later rules.
+type ChatFilter struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	FilterRule
+	re *regexp.Regexp
+}
+
+func NewChatFilter(rules []FilterRule) (*ChatFilter, error) {
+	f := &ChatFilter{}
+	for _, rule := range rules {
+		switch rule.Action {
+		case FilterBlock, FilterMask, FilterFlag:
+		default:
+			return nil, fmt.Errorf("filter rule %q: unknown action %q", rule.Pattern, rule.Action)
+		}
+
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("filter rule %q: %w", rule.Pattern, err)
+		}
+		f.rules = append(f.rules, compiledRule{FilterRule: rule, re: re})
+	}
+	return f, nil
+}
+
+// LoadChatFilter reads a JSON array of FilterRules.
+func LoadChatFilter(r io.Reader) (*ChatFilter, error) {
+	var rules []FilterRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid chat filter: %w", err)
+	}
+	return NewChatFilter(rules)
+}
+
+// FilterResult is the outcome of running text through a ChatFilter.
+type FilterResult struct {
+	Text    string
+	Blocked bool
+	Masked  []string // patterns that masked part of the text
+	Flagged []string // patterns that flagged the text
+}
+
+func (f *ChatFilter) Apply(text string) FilterResult {
+	result := FilterResult{Text: text}
+	if f == nil {
+		return result
+	}
+
+	for _, rule := range f.rules {
+		if !rule.re.MatchString(result.Text) {
+			continue
+		}
+
+		switch rule.Action {
+		case FilterBlock:
+			result.Blocked = true
+			return result
+		case FilterMask:
+			result.Text = rule.re.ReplaceAllStringFunc(result.Text, func(m string) string {
+				return strings.Repeat("*", utf8.RuneCountInString(m))
+			})
+			result.Masked = append(result.Masked, rule.Pattern)
+		case FilterFlag:
+			result.Flagged = append(result.Flagged, rule.Pattern)
+		}
+	}
+	return result
+}
+
+type AuditKind string
+
+const (
+	AuditFilterBlocked AuditKind = "filter_blocked"
+	AuditFilterMasked  AuditKind = "filter_masked"
+	AuditFilterFlagged AuditKind = "filter_flagged"
+	AuditMute          AuditKind = "mute"
+	AuditKick          AuditKind = "kick"
+	AuditReport        AuditKind = "report"
+	AuditInviteCreated AuditKind = "invite_created"
+	AuditInviteRevoked AuditKind = "invite_revoked"
+)
+
+const (
+	// maxAuditEntries caps the moderation log; the oldest entries go
+	// first. Reports are capped separately at maxReports so a flood of
+	// them cannot push mutes and kicks out of the log.
+	maxAuditEntries = 1000
+	maxReports      = 200
+
+	// A player may file reportRateLimit reports in any reportRateWindow
+	reportRateLimit  = 3
+	reportRateWindow = time.Minute
+
+	// reportableEvents is how many recent events a report can cite.
+	reportableEvents = 500
+)
+
+// ActionReport identifies reports in rate-limit errors.
+const ActionReport Action = "report"
+
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	Kind      AuditKind `json:"kind"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	TargetID  string    `json:"target_id,omitempty"`
+	EventIDs  []string  `json:"event_ids,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// audit appends an entry to the moderation log, dropping the oldest report
+// or entry once a cap is reached. The caller must hold g.Mu.
+func (g *Game) audit(entry AuditEntry) {
+	g.auditSeq++
+	entry.ID = g.auditSeq
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	g.auditLog = append(g.auditLog, entry)
+
+	if entry.Kind == AuditReport {
+		reports, oldest := 0, -1
+		for i, e := range g.auditLog {
+			if e.Kind == AuditReport {
+				if oldest < 0 {
+					oldest = i
+				}
+				reports++
+			}
+		}
+		if reports > maxReports {
+			g.auditLog = append(g.auditLog[:oldest], g.auditLog[oldest+1:]...)
+		}
+	}
+	if len(g.auditLog) > maxAuditEntries {
+		g.auditLog = g.auditLog[len(g.auditLog)-maxAuditEntries:]
+	}
+}
+
+// AuditLog returns moderation entries after sinceID, optionally restricted to
+// one kind.
+func (g *Game) AuditLog(kind AuditKind, sinceID int) []AuditEntry {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	entries := make([]AuditEntry, 0)
+	for _, entry := range g.auditLog {
+		if entry.ID <= sinceID || (kind != "" && entry.Kind != kind) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// AdminActorID is the actor for moderation done by a server admin rather
+// than a player. Player IDs are longer, so it never names a player.
+const AdminActorID = "admin"
+
+// canModerate reports whether actorID may mute and kick. The caller must
+// hold g.Mu.
+func (g *Game) canModerate(actorID string) bool {
+	return actorID == AdminActorID || (actorID != "" && actorID == g.OwnerID)
+}
+
+// Mute stops a player from chatting for duration.
+func (g *Game) Mute(actorID, targetID string, duration time.Duration, reason string) error {
+	if duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+
+	g.Mu.Lock()
+	if !g.canModerate(actorID) {
+		g.Mu.Unlock()
+		return fmt.Errorf("only the game owner can mute players")
+	}
+
+	target := g.Players[targetID]
+	if target == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	target.mutedUntil = now.Add(duration)
+	g.audit(AuditEntry{
+		Kind:      AuditMute,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Detail:    withReason(fmt.Sprintf("muted for %s", duration), reason),
+		Timestamp: now,
+	})
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(Event{
+		Type:       EventPlayerMuted,
+		PlayerID:   targetID,
+		Message:    fmt.Sprintf("You have been muted for %s", duration),
+		Recipients: []string{targetID},
+	})
+	return nil
+}
+
+// Kick removes a player from the game and closes their event streams. A
+// positive duration also bars them from rejoining until it expires, by
+// account if they joined with one. Names are not banned: anyone can pick
+// any name, so a name ban would lock out strangers and not the player.
+func (g *Game) Kick(actorID, targetID string, duration time.Duration, reason string) error {
+	if duration < 0 {
+		return fmt.Errorf("duration must not be negative")
+	}
+
+	g.Mu.Lock()
+	if !g.canModerate(actorID) {
+		g.Mu.Unlock()
+		return fmt.Errorf("only the game owner can kick players")
+	}
+	if targetID == actorID {
+		g.Mu.Unlock()
+		return fmt.Errorf("cannot kick yourself")
+	}
+
+	target := g.Players[targetID]
+	if target == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	g.disengage(target)
+	leaveEvents := g.leaveParty(target)
+	leaveEvents = append(leaveEvents, g.cancelTrades(target, target.Name+" left the game")...)
+	delete(g.Players, targetID)
+	if duration > 0 {
+		for _, key := range banKeys(target) {
+			g.bans[key] = now.Add(duration)
+		}
+	}
+
+	g.audit(AuditEntry{
+		Kind:      AuditKick,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Detail:    withReason(fmt.Sprintf("kicked %s, banned for %s", target.Name, duration), reason),
+		Timestamp: now,
+	})
+	g.Mu.Unlock()
+
+	g.disconnectPlayer(targetID)
+	g.broadcastEvents(leaveEvents)
+	g.BroadcastEvent(Event{
+		Type:     EventPlayerKicked,
+		PlayerID: targetID,
+		Message:  fmt.Sprintf("%s was removed from the game", target.Name),
+		Global:   true,
+	})
+	return nil
+}
+
+func withReason(detail, reason string) string {
+	if reason == "" {
+		return detail
+	}
+	return detail + ": " + reason
+}
+
+// banKeys are the identities a ban on player covers: the subject of their
+// player token and, if they joined with one, their account.
+func banKeys(player *Player) []string {
+	keys := []string{"player:" + player.ID}
+	if player.AccountID != "" {
+		keys = append(keys, "account:"+player.AccountID)
+	}
+	return keys
+}
+
+// banned reports whether a ban covers player. The caller must hold g.Mu.
+func (g *Game) banned(player *Player, now time.Time) bool {
+	for _, key := range banKeys(player) {
+		if until, ok := g.bans[key]; ok && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventRecord is who a broadcast event was about, kept so reports can be
+// checked against it.
+type eventRecord struct {
+	id       string
+	playerID string
+	targetID string
+}
+
+// recordEvent remembers a broadcast event, and any batched inside it, for
+// reports. The caller must hold g.ClientsMu.
+func (g *Game) recordEvent(event Event) {
+	g.recentEvents = append(g.recentEvents, eventRecord{id: event.ID, playerID: event.PlayerID, targetID: event.TargetID})
+	for _, e := range event.Events {
+		g.recentEvents = append(g.recentEvents, eventRecord{id: event.ID, playerID: e.PlayerID, targetID: e.TargetID})
+	}
+	if len(g.recentEvents) > reportableEvents {
+		g.recentEvents = g.recentEvents[len(g.recentEvents)-reportableEvents:]
+	}
+}
+
+// eventInvolves reports whether a recent event has id and names playerID as
+// its actor or target.
+func (g *Game) eventInvolves(id, playerID string) bool {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+
+	for _, e := range g.recentEvents {
+		if e.id == id && (e.playerID == playerID || e.targetID == playerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReportRate enforces the per-player report rate limit. The caller must
+// hold g.Mu.
+func (p *Player) checkReportRate(now time.Time) error {
+	recent := p.reportSent[:0]
+	for _, t := range p.reportSent {
+		if now.Sub(t) < reportRateWindow {
+			recent = append(recent, t)
+		}
+	}
+	p.reportSent = recent
+
+	if len(recent) >= reportRateLimit {
+		return &CooldownError{Action: ActionReport, RetryAfter: recent[0].Add(reportRateWindow).Sub(now)}
+	}
+	return nil
+}
+
+// Report records a player's complaint about another player's events for the
+// owner to review. Every event cited must be a recent one involving the
+// target.
+func (g *Game) Report(reporterID, targetID string, eventIDs []string, reason string) error {
+	if len(eventIDs) == 0 {
+		return fmt.Errorf("at least one event ID is required")
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+
+	reporter := g.Players[reporterID]
+	if reporter == nil || g.Players[targetID] == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := reporter.checkReportRate(now); err != nil {
+		return err
+	}
+
+	for _, id := range eventIDs {
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return fmt.Errorf("invalid event ID: %s", id)
+		}
+		if !g.eventInvolves(id, targetID) {
+			return fmt.Errorf("event %s not found or does not involve the reported player", id)
+		}
+	}
+
+	reporter.reportSent = append(reporter.reportSent, now)
+
+	g.audit(AuditEntry{
+		Kind:     AuditReport,
+		ActorID:  reporterID,
+		TargetID: targetID,
+		EventIDs: eventIDs,
+		Detail:   reason,
+	})
+	return nil
+}
+
+// DisconnectAll closes every player and spectator event stream, e.g. when
+// the game is deleted.
+func (g *Game) DisconnectAll() {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+
+	for ch := range g.clientPlayers {
+		delete(g.clientPlayers, ch)
+		close(ch)
+	}
+	for ch := range g.spectators {
+		delete(g.spectators, ch)
+		close(ch)
+	}
+}
+
+// disconnectPlayer closes every event stream belonging to playerID.
+func (g *Game) disconnectPlayer(playerID string) {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+
+	for ch, pid := range g.clientPlayers {
+		if pid == playerID {
+			delete(g.clientPlayers, ch)
+			close(ch)
+		}
+	}
+}
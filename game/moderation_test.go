@@ -0,0 +1,177 @@
+package game
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestChatFilter(t *testing.T) {
+	filter, err := NewChatFilter([]FilterRule{
+		{Pattern: "badword", Action: FilterBlock},
+		{Pattern: "darn", Action: FilterMask},
+		{Pattern: "scam", Action: FilterFlag},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text        string
+		wantText    string
+		wantBlocked bool
+		wantFlagged bool
+	}{
+		{"hello", "hello", false, false},
+		{"a BADWORD here", "a BADWORD here", true, false},
+		{"oh Darn it", "oh **** it", false, false},
+		{"free scam", "free scam", false, true},
+	}
+	for _, tt := range tests {
+		got := filter.Apply(tt.text)
+		if got.Text != tt.wantText || got.Blocked != tt.wantBlocked || (len(got.Flagged) > 0) != tt.wantFlagged {
+			t.Errorf("Apply(%q) = %+v", tt.text, got)
+		}
+	}
+
+	if _, err := NewChatFilter([]FilterRule{{Pattern: "x", Action: "explode"}}); err == nil {
+		t.Error("unknown filter action accepted")
+	}
+	if _, err := NewChatFilter([]FilterRule{{Pattern: "(", Action: FilterBlock}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestKickBans(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		rejoin   Player
+		want     error
+	}{
+		{"same player", time.Hour, Player{ID: "target", Name: "target"}, ErrBanned},
+		{"same account, new name", time.Hour, Player{ID: "new", Name: "someone", AccountID: "acct"}, ErrBanned},
+		{"different player, same name", time.Hour, Player{ID: "new", Name: "target"}, nil},
+		{"kick without a ban", 0, Player{ID: "new", Name: "target", AccountID: "acct"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			addTestPlayer(t, g, "owner")
+			target := &Player{ID: "target", Name: "target", AccountID: "acct", CurrentLocation: firstLocation(g), Health: 100}
+			if err := g.AddPlayer(target, "", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := g.Kick("owner", "target", tt.duration, "spam"); err != nil {
+				t.Fatalf("Kick() error = %v", err)
+			}
+
+			rejoin := tt.rejoin
+			rejoin.CurrentLocation = firstLocation(g)
+			if err := g.AddPlayer(&rejoin, "", ""); !errors.Is(err, tt.want) {
+				t.Errorf("rejoin error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestModerationNeedsOwner(t *testing.T) {
+	g := newTestGame(t, Options{})
+	addTestPlayer(t, g, "owner")
+	addTestPlayer(t, g, "other")
+
+	if err := g.Kick("other", "owner", 0, ""); err == nil {
+		t.Error("a non-owner kicked the owner")
+	}
+	if err := g.Mute("other", "owner", time.Minute, ""); err == nil {
+		t.Error("a non-owner muted the owner")
+	}
+	if err := g.Kick("owner", "owner", 0, ""); err == nil {
+		t.Error("the owner kicked themselves")
+	}
+	if err := g.Kick(AdminActorID, "other", 0, ""); err != nil {
+		t.Errorf("admin kick: %v", err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	g := newTestGame(t, Options{})
+	addTestPlayer(t, g, "reporter")
+	addTestPlayer(t, g, "target")
+	addTestPlayer(t, g, "bystander")
+
+	g.BroadcastEvent(Event{Type: EventChatSay, PlayerID: "target", Message: "rude"})
+	g.BroadcastEvent(Event{Type: EventChatSay, PlayerID: "bystander", Message: "polite"})
+	byTarget := strconv.FormatUint(g.eventSeq.Load()-1, 10)
+	byBystander := strconv.FormatUint(g.eventSeq.Load(), 10)
+
+	tests := []struct {
+		name     string
+		target   string
+		eventIDs []string
+		wantErr  bool
+	}{
+		{"no events", "target", nil, true},
+		{"malformed ID", "target", []string{"abc"}, true},
+		{"unknown event", "target", []string{"999999"}, true},
+		{"event not involving the target", "target", []string{byBystander}, true},
+		{"unknown target", "nobody", []string{byTarget}, true},
+		{"valid", "target", []string{byTarget}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.Report("reporter", tt.target, tt.eventIDs, "abuse")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Report() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("rate limited", func(t *testing.T) {
+		var err error
+		for range reportRateLimit {
+			err = g.Report("reporter", "target", []string{byTarget}, "again")
+		}
+		var cooldownErr *CooldownError
+		if !errors.As(err, &cooldownErr) || cooldownErr.Action != ActionReport {
+			t.Errorf("error = %v, want a report cooldown", err)
+		}
+	})
+}
+
+func TestAuditCaps(t *testing.T) {
+	g := newTestGame(t, Options{})
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	g.audit(AuditEntry{Kind: AuditKick})
+	for range maxReports + 10 {
+		g.audit(AuditEntry{Kind: AuditReport})
+	}
+
+	reports := 0
+	for _, e := range g.auditLog {
+		if e.Kind == AuditReport {
+			reports++
+		}
+	}
+	if reports != maxReports {
+		t.Errorf("reports = %d, want %d", reports, maxReports)
+	}
+	if g.auditLog[0].Kind != AuditKick {
+		t.Error("reports pushed a kick out of the log")
+	}
+
+	for range maxAuditEntries {
+		g.audit(AuditEntry{Kind: AuditMute})
+	}
+	if len(g.auditLog) != maxAuditEntries {
+		t.Errorf("audit log = %d entries, want %d", len(g.auditLog), maxAuditEntries)
+	}
+	if last := g.auditLog[len(g.auditLog)-1]; last.ID != g.auditSeq {
+		t.Errorf("last entry ID = %d, want %d", last.ID, g.auditSeq)
+	}
+}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+type Mode string
+
+const (
+	// ModeRealTime processes every action as soon as it arrives.
+	ModeRealTime Mode = "realtime"
+	// ModeTurnBased only accepts actions from the player whose turn it is.
+	ModeTurnBased Mode = "turn_based"
+	// ModeSimultaneous queues moves and attacks and resolves them together
+	// at the end of each tick window.
+	ModeSimultaneous Mode = "simultaneous"
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeRealTime:
+		return ModeRealTime, nil
+	case ModeTurnBased:
+		return ModeTurnBased, nil
+	case ModeSimultaneous:
+		return ModeSimultaneous, nil
+	}
+	return "", fmt.Errorf("unknown game mode: %s", s)
+}
+
+// beginAction checks whether the game's mode lets player act right now.
+// The caller must hold g.Mu.
+func (g *Game) beginAction(player *Player, action Action, now time.Time) error {
+	switch g.Mode {
+	case ModeTurnBased:
+		if g.turns.activePlayerID() != player.ID {
+			return fmt.Errorf("not your turn")
+		}
+		return nil
+	case ModeSimultaneous:
+		return fmt.Errorf("actions must be queued in simultaneous games")
+	default:
+		return player.checkCooldown(action, now)
+	}
+}
+
+// finishAction records a successful action according to the game's mode and
+// returns any events that result. The caller must hold g.Mu.
+func (g *Game) finishAction(player *Player, action Action, now time.Time) []Event {
+	switch g.Mode {
+	case ModeTurnBased:
+		return g.endTurn(now, fmt.Sprintf("%s used their turn to %s", player.Name, action))
+	default:
+		player.startCooldown(action, now, g.travelScale(action))
+		return nil
+	}
+}
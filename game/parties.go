@@ -0,0 +1,312 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"game-api/utils"
+)
+
+const (
+	MaxPartySize = 4
+
+	// How long a party invitation stays open
+	partyInviteTimeout = time.Minute
+)
+
+type Party struct {
+	ID       string   `json:"id"`
+	LeaderID string   `json:"leader_id"`
+	Members  []string `json:"members"`
+
+	invites map[string]time.Time // invited player ID -> expiry
+}
+
+// allies reports whether two players are on the same side: members of the
+// same party or of the same team. The caller must hold g.Mu.
+func allies(a, b *Player) bool {
+	if a.PartyID != "" && a.PartyID == b.PartyID {
+		return true
+	}
+	return a.Team != "" && a.Team == b.Team
+}
+
+// partyEvent builds an event only the party's members (plus any extra
+// recipients) can see. The caller must hold g.Mu.
+func partyEvent(party *Party, eventType EventType, playerID, message string, extra ...string) Event {
+	recipients := append(append([]string(nil), party.Members...), extra...)
+	return Event{
+		Type:       eventType,
+		PlayerID:   playerID,
+		Message:    message,
+		Recipients: recipients,
+	}
+}
+
+// GetParty returns a copy of the player's party, or nil if they have none.
+func (g *Game) GetParty(playerID string) *Party {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	player := g.Players[playerID]
+	if player == nil || player.PartyID == "" {
+		return nil
+	}
+
+	party := g.parties[player.PartyID]
+	if party == nil {
+		return nil
+	}
+	return &Party{
+		ID:       party.ID,
+		LeaderID: party.LeaderID,
+		Members:  append([]string(nil), party.Members...),
+	}
+}
+
+// InviteToParty invites targetID to the inviter's party, creating a party led
+// by the inviter if they are not in one yet. A party nobody joins is removed
+// once its invitations lapse.
+func (g *Game) InviteToParty(inviterID, targetID string) error {
+	g.Mu.Lock()
+	inviter := g.Players[inviterID]
+	target := g.Players[targetID]
+	if inviter == nil || target == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+	if inviterID == targetID {
+		g.Mu.Unlock()
+		return fmt.Errorf("cannot invite yourself")
+	}
+	if target.PartyID != "" {
+		g.Mu.Unlock()
+		return fmt.Errorf("%s is already in a party", target.Name)
+	}
+	if len(g.Teams) > 0 && inviter.Team != target.Team {
+		g.Mu.Unlock()
+		return fmt.Errorf("parties cannot span teams")
+	}
+
+	party := g.parties[inviter.PartyID]
+	if party == nil {
+		party = &Party{
+			ID:       utils.GenerateID(8),
+			LeaderID: inviterID,
+			Members:  []string{inviterID},
+			invites:  make(map[string]time.Time),
+		}
+		g.parties[party.ID] = party
+		inviter.PartyID = party.ID
+	}
+
+	if party.LeaderID != inviterID {
+		g.Mu.Unlock()
+		return fmt.Errorf("only the party leader can invite")
+	}
+	if len(party.Members) >= MaxPartySize {
+		g.Mu.Unlock()
+		return fmt.Errorf("party is full")
+	}
+
+	party.invites[targetID] = time.Now().Add(partyInviteTimeout)
+	event := partyEvent(party, EventPartyInvite, inviterID,
+		fmt.Sprintf("%s invited %s to the party", inviter.Name, target.Name), targetID)
+	event.TargetID = party.ID
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(event)
+	return nil
+}
+
+// AcceptPartyInvite joins the party that invited the player.
+func (g *Game) AcceptPartyInvite(playerID, partyID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+	if player.PartyID != "" {
+		g.Mu.Unlock()
+		return fmt.Errorf("already in a party")
+	}
+
+	party := g.parties[partyID]
+	if party == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("party not found")
+	}
+
+	expires, invited := party.invites[playerID]
+	if !invited || time.Now().After(expires) {
+		g.Mu.Unlock()
+		return fmt.Errorf("no open invitation to this party")
+	}
+	if len(party.Members) >= MaxPartySize {
+		g.Mu.Unlock()
+		return fmt.Errorf("party is full")
+	}
+
+	delete(party.invites, playerID)
+	party.Members = append(party.Members, playerID)
+	player.PartyID = party.ID
+	event := partyEvent(party, EventPartyJoined, playerID, fmt.Sprintf("%s joined the party", player.Name))
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(event)
+	return nil
+}
+
+func (g *Game) LeaveParty(playerID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+	if player.PartyID == "" {
+		g.Mu.Unlock()
+		return fmt.Errorf("not in a party")
+	}
+
+	events := g.leaveParty(player)
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return nil
+}
+
+// leaveParty removes player from their party, handing leadership on and
+// disbanding parties with a single member left. The caller must hold g.Mu.
+func (g *Game) leaveParty(player *Player) []Event {
+	party := g.parties[player.PartyID]
+	player.PartyID = ""
+	if party == nil {
+		return nil
+	}
+
+	// The leaver still sees their own departure
+	events := []Event{partyEvent(party, EventPartyLeft, player.ID, fmt.Sprintf("%s left the party", player.Name))}
+
+	members := party.Members[:0]
+	for _, id := range party.Members {
+		if id != player.ID {
+			members = append(members, id)
+		}
+	}
+	party.Members = members
+
+	if len(party.Members) <= 1 {
+		for _, id := range party.Members {
+			if p := g.Players[id]; p != nil {
+				p.PartyID = ""
+			}
+		}
+		delete(g.parties, party.ID)
+		return events
+	}
+
+	if party.LeaderID == player.ID {
+		party.LeaderID = party.Members[0]
+	}
+	return events
+}
+
+// expirePartyInvites drops lapsed party invitations. A party whose leader is
+// still alone once its last invitation lapses was never formed, so it is
+// removed. The caller must hold g.Mu.
+func (g *Game) expirePartyInvites(now time.Time) []Event {
+	var events []Event
+	for id, party := range g.parties {
+		for target, expires := range party.invites {
+			if now.After(expires) {
+				delete(party.invites, target)
+			}
+		}
+		if len(party.Members) > 1 || len(party.invites) > 0 {
+			continue
+		}
+
+		events = append(events, partyEvent(party, EventPartyLeft, party.LeaderID, "Nobody accepted the invitation, so the party disbanded"))
+		for _, member := range party.Members {
+			if p := g.Players[member]; p != nil {
+				p.PartyID = ""
+			}
+		}
+		delete(g.parties, id)
+	}
+	return events
+}
+
+// assignTeam puts a new player on the requested team, or on the team with the
+// fewest members if none was requested. The caller must hold g.Mu.
+func (g *Game) assignTeam(player *Player, requested string) error {
+	if len(g.Teams) == 0 {
+		if requested != "" {
+			return fmt.Errorf("this game has no teams")
+		}
+		return nil
+	}
+
+	if requested != "" {
+		if !contains(g.Teams, requested) {
+			return fmt.Errorf("unknown team: %s", requested)
+		}
+		player.Team = requested
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, p := range g.Players {
+		counts[p.Team]++
+	}
+
+	best := g.Teams[0]
+	for _, team := range g.Teams[1:] {
+		if counts[team] < counts[best] {
+			best = team
+		}
+	}
+	player.Team = best
+	return nil
+}
+
+// scoreDefeat credits killer's team for defeating victim and declares a
+// winner once a team reaches the score limit. The caller must hold g.Mu.
+func (g *Game) scoreDefeat(killer, victim *Player) []Event {
+	if killer.Team == "" || killer.Team == victim.Team || g.Winner != "" {
+		return nil
+	}
+
+	g.TeamScores[killer.Team]++
+	score := g.TeamScores[killer.Team]
+	events := []Event{{
+		Type:     EventTeamScored,
+		PlayerID: killer.ID,
+		Message:  fmt.Sprintf("Team %s scores (%d)", killer.Team, score),
+		Global:   true,
+	}}
+
+	if g.ScoreLimit > 0 && score >= g.ScoreLimit {
+		g.Winner = killer.Team
+		events = append(events, Event{
+			Type:    EventTeamWon,
+			Message: fmt.Sprintf("Team %s wins!", killer.Team),
+			Global:  true,
+		})
+	}
+	return events
+}
+
+// Scoreboard returns a copy of the team scores.
+func (g *Game) Scoreboard() map[string]int {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	scores := make(map[string]int, len(g.TeamScores))
+	for team, score := range g.TeamScores {
+		scores[team] = score
+	}
+	return scores
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInviteToPartyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		prepare func(g *Game)
+		inviter string
+		target  string
+	}{
+		{"unknown target", Options{}, func(*Game) {}, "a", "nobody"},
+		{"yourself", Options{}, func(*Game) {}, "a", "a"},
+		{"target already in a party", Options{}, func(g *Game) {
+			g.InviteToParty("b", "c")
+			g.AcceptPartyInvite("c", g.Players["b"].PartyID)
+		}, "a", "c"},
+		{"not the leader", Options{}, func(g *Game) {
+			g.InviteToParty("b", "a")
+			g.AcceptPartyInvite("a", g.Players["b"].PartyID)
+		}, "a", "c"},
+		{"across teams", Options{Teams: []string{"red", "blue"}}, func(*Game) {}, "a", "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, tt.opts)
+			for _, id := range []string{"a", "b", "c"} {
+				addTestPlayer(t, g, id)
+			}
+			tt.prepare(g)
+
+			if err := g.InviteToParty(tt.inviter, tt.target); err == nil {
+				t.Error("InviteToParty() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestPartyLifecycle(t *testing.T) {
+	g := newTestGame(t, Options{})
+	for _, id := range []string{"a", "b"} {
+		addTestPlayer(t, g, id)
+	}
+
+	if err := g.AcceptPartyInvite("b", "nope"); err == nil {
+		t.Error("accepted a party that does not exist")
+	}
+	if err := g.InviteToParty("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	partyID := g.Players["a"].PartyID
+	if err := g.AcceptPartyInvite("b", partyID); err != nil {
+		t.Fatal(err)
+	}
+	if !allies(g.Players["a"], g.Players["b"]) {
+		t.Error("party members are not allies")
+	}
+
+	// A party of one disbands
+	if err := g.LeaveParty("a"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Players["b"].PartyID != "" || g.GetParty("b") != nil {
+		t.Error("a party with one member left was not disbanded")
+	}
+}
+
+func TestAssignTeam(t *testing.T) {
+	g := newTestGame(t, Options{Teams: []string{"red", "blue"}})
+
+	tests := []struct {
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{"", "red", false},
+		{"", "blue", false},
+		{"red", "red", false},
+		{"", "blue", false},
+		{"green", "", true},
+	}
+	for i, tt := range tests {
+		p := &Player{ID: string(rune('a' + i)), Name: string(rune('a' + i)), CurrentLocation: firstLocation(g), Health: 100}
+		err := g.AddPlayer(p, tt.requested, "")
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("join %d: error = %v, wantErr %v", i, err, tt.wantErr)
+		}
+		if err == nil && p.Team != tt.want {
+			t.Errorf("join %d: team = %s, want %s", i, p.Team, tt.want)
+		}
+	}
+}
+
+func TestUnacceptedPartyDisbands(t *testing.T) {
+	g := newTestGame(t, Options{})
+	a := addTestPlayer(t, g, "a")
+	addTestPlayer(t, g, "b")
+	addTestPlayer(t, g, "c")
+	if err := g.InviteToParty("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.InviteToParty("a", "c"); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	party := g.parties[a.PartyID]
+	party.invites["b"] = time.Now().Add(-time.Second)
+
+	// c's invitation is still open
+	g.expirePartyInvites(time.Now())
+	if g.parties[party.ID] == nil {
+		t.Fatal("party removed while an invitation is open")
+	}
+
+	events := g.expirePartyInvites(time.Now().Add(partyInviteTimeout + time.Second))
+	if len(g.parties) != 0 || a.PartyID != "" {
+		t.Errorf("party of one kept after its invitations lapsed")
+	}
+	if countEvents(events, EventPartyLeft) != 1 {
+		t.Errorf("leader not told the party disbanded: %+v", events)
+	}
+}
@@ -1,17 +1,76 @@
 package game
 
-import "math/rand"
+import (
+	"math/rand"
+	"time"
+)
 
 type Player struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	CurrentLocation string `json:"current_location"`
-	Health          int    `json:"health"`
-	Strength        int    `json:"strength"`
-	Dexterity       int    `json:"dexterity"`
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	CurrentLocation string          `json:"current_location"`
+	Health          int             `json:"health"`
+	Strength        int             `json:"strength"`
+	Dexterity       int             `json:"dexterity"`
+	Effects         []*StatusEffect `json:"effects,omitempty"`
+	PartyID         string          `json:"party_id,omitempty"`
+	Team            string          `json:"team,omitempty"`
+	Inventory       []*Item         `json:"inventory,omitempty"`
+	Hidden          bool            `json:"hidden,omitempty"`
+	AccountID       string          `json:"account_id,omitempty"` // set when an account owns this character
+	Bot             bool            `json:"bot,omitempty"`
+
+	cooldowns  map[Action]time.Time
+	engaged    map[string]time.Time // opponent ID -> last exchange
+	chatSent   []time.Time          // recent chat messages, for rate limiting
+	reportSent []time.Time          // recent reports, for rate limiting
+	mutedUntil time.Time
+	tradeID    string
+	quests     map[string]*QuestProgress
+	defeated   bool // set by defeat, so it runs once per death
 }
 
 // RollAttribute generates a random attribute value (3-18, simulating 3d6)
 func RollAttribute() int {
 	return rand.Intn(16) + 3 // Random number between 3 and 18 inclusive
 }
+
+// EffectiveStrength is Strength after status effect modifiers (minimum 1).
+func (p *Player) EffectiveStrength() int {
+	str := p.Strength
+	for _, e := range p.Effects {
+		str += EffectDefinitions[e.Kind].StrengthMod * e.Stacks
+	}
+	return max(str, 1)
+}
+
+// EffectiveDexterity is Dexterity after status effect modifiers (minimum 1).
+func (p *Player) EffectiveDexterity() int {
+	dex := p.Dexterity
+	for _, e := range p.Effects {
+		dex += EffectDefinitions[e.Kind].DexterityMod * e.Stacks
+	}
+	return max(dex, 1)
+}
+
+// DamageBonus is the flat damage status effects add to the player's attacks.
+func (p *Player) DamageBonus() int {
+	bonus := 0
+	for _, e := range p.Effects {
+		bonus += EffectDefinitions[e.Kind].DamageMod * e.Stacks
+	}
+	return bonus
+}
+
+// Incapacitated reports whether an effect currently prevents the player
+// from moving or attacking. Effects are only removed on the next effect
+// tick, so one that has run out but not been removed yet does not count.
+func (p *Player) Incapacitated() bool {
+	now := time.Now()
+	for _, e := range p.Effects {
+		if EffectDefinitions[e.Kind].Incapacitates && now.Before(e.ExpiresAt) {
+			return true
+		}
+	}
+	return false
+}
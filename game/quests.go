@@ -0,0 +1,436 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ObjectiveKind string
+
+const (
+	ObjectiveVisit   ObjectiveKind = "visit"   // reach Location
+	ObjectiveDefeat  ObjectiveKind = "defeat"  // defeat Count players or NPCs
+	ObjectiveDeliver ObjectiveKind = "deliver" // carry Item to Location
+)
+
+// Objective is one step of a quest. Visits and deliveries name either a
+// Location, which only exists in worlds generated with the same names, or a
+// Biome, which any location of that biome satisfies.
+type Objective struct {
+	Kind     ObjectiveKind `json:"kind"`
+	Location string        `json:"location,omitempty"`
+	Biome    Biome         `json:"biome,omitempty"`
+	Target   string        `json:"target,omitempty"` // "player" or "npc", for defeat
+	Item     string        `json:"item,omitempty"`
+	Count    int           `json:"count,omitempty"`
+}
+
+type Reward struct {
+	Items     []string `json:"items,omitempty"`
+	Health    int      `json:"health,omitempty"`
+	Strength  int      `json:"strength,omitempty"`
+	Dexterity int      `json:"dexterity,omitempty"`
+}
+
+// Quest is a quest definition. A quest becomes active for a player once
+// every quest in Prerequisites is complete, and all objectives progress in
+// parallel.
+type Quest struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	Prerequisites []string    `json:"prerequisites,omitempty"`
+	Objectives    []Objective `json:"objectives"`
+	Rewards       Reward      `json:"rewards"`
+}
+
+// QuestProgress tracks one player's progress on one quest.
+type QuestProgress struct {
+	QuestID     string     `json:"quest_id"`
+	Progress    []int      `json:"progress"` // per objective
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// LoadQuests reads and validates a JSON array of quest definitions.
+func LoadQuests(r io.Reader) ([]*Quest, error) {
+	var quests []*Quest
+	if err := json.NewDecoder(r).Decode(&quests); err != nil {
+		return nil, fmt.Errorf("invalid quest file: %w", err)
+	}
+	if err := validateQuests(quests); err != nil {
+		return nil, err
+	}
+	return quests, nil
+}
+
+func validateQuests(quests []*Quest) error {
+	ids := make(map[string]bool)
+	for _, q := range quests {
+		if q.ID == "" {
+			return fmt.Errorf("quest %q has no id", q.Name)
+		}
+		if ids[q.ID] {
+			return fmt.Errorf("duplicate quest id %q", q.ID)
+		}
+		ids[q.ID] = true
+	}
+
+	for _, q := range quests {
+		for _, pre := range q.Prerequisites {
+			if !ids[pre] {
+				return fmt.Errorf("quest %q: unknown prerequisite %q", q.ID, pre)
+			}
+		}
+		if len(q.Objectives) == 0 {
+			return fmt.Errorf("quest %q has no objectives", q.ID)
+		}
+		for i, o := range q.Objectives {
+			place := o.Location != "" || o.Biome != ""
+			switch {
+			case o.Location != "" && o.Biome != "":
+				return fmt.Errorf("quest %q objective %d: give a location or a biome, not both", q.ID, i)
+			case o.Biome != "" && !o.Biome.valid():
+				return fmt.Errorf("quest %q objective %d: unknown biome %q", q.ID, i, o.Biome)
+			case o.Kind == ObjectiveVisit && !place:
+				return fmt.Errorf("quest %q objective %d: visit needs a location or biome", q.ID, i)
+			case o.Kind == ObjectiveDeliver && (!place || o.Item == ""):
+				return fmt.Errorf("quest %q objective %d: deliver needs a location or biome, and an item", q.ID, i)
+			case o.Kind == ObjectiveDefeat && o.Target != "player" && o.Target != "npc":
+				return fmt.Errorf("quest %q objective %d: defeat target must be player or npc", q.ID, i)
+			case o.Kind != ObjectiveVisit && o.Kind != ObjectiveDeliver && o.Kind != ObjectiveDefeat:
+				return fmt.Errorf("quest %q objective %d: unknown kind %q", q.ID, i, o.Kind)
+			}
+		}
+		for _, name := range q.Rewards.Items {
+			if NewItem(name) == nil {
+				return fmt.Errorf("quest %q: unknown reward item %q", q.ID, name)
+			}
+		}
+	}
+	return checkPrerequisiteCycles(quests)
+}
+
+// checkPrerequisiteCycles rejects quests that depend, directly or through
+// other quests, on themselves. They could never start.
+func checkPrerequisiteCycles(quests []*Quest) error {
+	byID := make(map[string]*Quest, len(quests))
+	for _, q := range quests {
+		byID[q.ID] = q
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(quests))
+	var visit func(q *Quest, path []string) error
+	visit = func(q *Quest, path []string) error {
+		switch state[q.ID] {
+		case visiting:
+			return fmt.Errorf("quest prerequisites form a cycle: %s", strings.Join(append(path, q.ID), " -> "))
+		case done:
+			return nil
+		}
+		state[q.ID] = visiting
+		for _, pre := range q.Prerequisites {
+			if err := visit(byID[pre], append(path, q.ID)); err != nil {
+				return err
+			}
+		}
+		state[q.ID] = done
+		return nil
+	}
+
+	for _, q := range quests {
+		if err := visit(q, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultQuests returns the built-in quests for a world. Since location
+// names are generated, the destinations are picked from the world itself:
+// the first and last locations by name.
+func DefaultQuests(locations map[string]*Location) []*Quest {
+	names := make([]string, 0, len(locations))
+	for _, loc := range locations {
+		names = append(names, loc.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	first, last := names[0], names[len(names)-1]
+
+	return []*Quest{
+		{
+			ID:          "explorer",
+			Name:        "Explorer",
+			Description: fmt.Sprintf("Find your way to %s.", first),
+			Objectives:  []Objective{{Kind: ObjectiveVisit, Location: first}},
+			Rewards:     Reward{Items: []string{"Torch"}, Health: 10},
+		},
+		{
+			ID:            "first-blood",
+			Name:          "First Blood",
+			Description:   "Defeat another adventurer.",
+			Prerequisites: []string{"explorer"},
+			Objectives:    []Objective{{Kind: ObjectiveDefeat, Target: "player", Count: 1}},
+			Rewards:       Reward{Strength: 1},
+		},
+		{
+			ID:            "courier",
+			Name:          "Courier",
+			Description:   fmt.Sprintf("Bring a Silver Ring to %s.", last),
+			Prerequisites: []string{"explorer"},
+			Objectives:    []Objective{{Kind: ObjectiveDeliver, Item: "Silver Ring", Location: last}},
+			Rewards:       Reward{Items: []string{"Health Potion"}, Dexterity: 1},
+		},
+	}
+}
+
+// worldQuests keeps the quests whose locations and biomes all exist in this
+// world.
+func worldQuests(quests []*Quest, locations map[string]*Location) []*Quest {
+	var result []*Quest
+	for _, q := range quests {
+		if len(unresolvedPlaces(q, locations)) == 0 {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+// unresolvedPlaces lists the places quest q needs that the world does not
+// have.
+func unresolvedPlaces(q *Quest, locations map[string]*Location) []string {
+	var missing []string
+	for _, o := range q.Objectives {
+		if o.Location == "" && o.Biome == "" {
+			continue
+		}
+		found := false
+		for _, loc := range locations {
+			if o.at(loc) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, o.place())
+		}
+	}
+	return missing
+}
+
+// at reports whether loc is where the objective takes place.
+func (o Objective) at(loc *Location) bool {
+	if o.Biome != "" {
+		return loc.Biome == o.Biome
+	}
+	return o.Location != "" && loc.Name == o.Location
+}
+
+func (o Objective) place() string {
+	if o.Biome != "" {
+		return "a " + string(o.Biome) + " location"
+	}
+	return o.Location
+}
+
+func (o Objective) required() int {
+	if o.Kind == ObjectiveDefeat && o.Count > 0 {
+		return o.Count
+	}
+	return 1
+}
+
+// QuestLog returns the game's quest definitions and the player's progress on
+// the quests they have started.
+func (g *Game) QuestLog(playerID string) ([]*Quest, []QuestProgress) {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	var progress []QuestProgress
+	if player := g.Players[playerID]; player != nil {
+		for _, q := range g.Quests {
+			if p := player.quests[q.ID]; p != nil {
+				copied := *p
+				copied.Progress = append([]int(nil), p.Progress...)
+				progress = append(progress, copied)
+			}
+		}
+	}
+	return g.Quests, progress
+}
+
+// startQuests activates every quest whose prerequisites the player has now
+// met. A quest that starts where it asks the player to go counts the visit
+// straight away. The caller must hold g.Mu.
+func (g *Game) startQuests(player *Player) []Event {
+	if player.quests == nil {
+		player.quests = make(map[string]*QuestProgress)
+	}
+
+	var events []Event
+	for _, q := range g.Quests {
+		if player.quests[q.ID] != nil {
+			continue
+		}
+
+		ready := true
+		for _, pre := range q.Prerequisites {
+			if p := player.quests[pre]; p == nil || !p.Completed {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			continue
+		}
+
+		player.quests[q.ID] = &QuestProgress{QuestID: q.ID, Progress: make([]int, len(q.Objectives))}
+		events = append(events, Event{
+			Type:       EventQuestStarted,
+			PlayerID:   player.ID,
+			TargetID:   q.ID,
+			Message:    fmt.Sprintf("New quest: %s - %s", q.Name, q.Description),
+			Recipients: []string{player.ID},
+		})
+	}
+
+	if loc := g.Locations[player.CurrentLocation]; len(events) > 0 && loc != nil {
+		events = append(events, g.advanceQuests(player, func(o Objective) int {
+			if o.Kind == ObjectiveVisit && o.at(loc) {
+				return 1
+			}
+			return 0
+		})...)
+	}
+	return events
+}
+
+// advanceQuests applies step to every unfinished objective of the player's
+// active quests. step returns how much progress to add for an objective.
+// The caller must hold g.Mu.
+func (g *Game) advanceQuests(player *Player, step func(o Objective) int) []Event {
+	var events []Event
+	for _, q := range g.Quests {
+		progress := player.quests[q.ID]
+		if progress == nil || progress.Completed {
+			continue
+		}
+
+		advanced := false
+		for i, o := range q.Objectives {
+			if progress.Progress[i] >= o.required() {
+				continue
+			}
+			if n := step(o); n > 0 {
+				progress.Progress[i] = min(progress.Progress[i]+n, o.required())
+				advanced = true
+			}
+		}
+		if !advanced {
+			continue
+		}
+
+		done := true
+		for i, o := range q.Objectives {
+			if progress.Progress[i] < o.required() {
+				done = false
+				break
+			}
+		}
+
+		if !done {
+			events = append(events, Event{
+				Type:       EventQuestProgress,
+				PlayerID:   player.ID,
+				TargetID:   q.ID,
+				Message:    fmt.Sprintf("Quest progress: %s", q.Name),
+				Recipients: []string{player.ID},
+			})
+			continue
+		}
+
+		now := time.Now()
+		progress.Completed = true
+		progress.CompletedAt = &now
+		g.grantReward(player, q.Rewards)
+		events = append(events, Event{
+			Type:       EventQuestCompleted,
+			PlayerID:   player.ID,
+			TargetID:   q.ID,
+			Message:    fmt.Sprintf("Quest completed: %s", q.Name),
+			Recipients: []string{player.ID},
+		})
+	}
+
+	if len(events) > 0 {
+		events = append(events, g.startQuests(player)...)
+	}
+	return events
+}
+
+// grantReward gives the player a quest's rewards. Health never rises above
+// the ruleset's starting health. The caller must hold g.Mu.
+func (g *Game) grantReward(player *Player, reward Reward) {
+	for _, name := range reward.Items {
+		if item := NewItem(name); item != nil {
+			player.Inventory = append(player.Inventory, item)
+		}
+	}
+	healed := reward.Health
+	if limit := g.Ruleset.StartingHealth; limit > 0 {
+		healed = min(healed, max(limit-player.Health, 0))
+	}
+	player.Health += healed
+	player.Strength += reward.Strength
+	player.Dexterity += reward.Dexterity
+}
+
+// questArrival records visits and deliveries when a player reaches a
+// location. Delivered items are taken from the player. The caller must hold
+// g.Mu.
+func (g *Game) questArrival(player *Player, location *Location) []Event {
+	return g.advanceQuests(player, func(o Objective) int {
+		if !o.at(location) {
+			return 0
+		}
+		switch o.Kind {
+		case ObjectiveVisit:
+			return 1
+		case ObjectiveDeliver:
+			for _, item := range player.Inventory {
+				if item.Name == o.Item {
+					player.takeItem(item.ID)
+					return 1
+				}
+			}
+		}
+		return 0
+	})
+}
+
+// questDefeat records that killer defeated a player (or an NPC). The caller
+// must hold g.Mu.
+func (g *Game) questDefeat(killer *Player, npc bool) []Event {
+	target := "player"
+	if npc {
+		target = "npc"
+	}
+	return g.advanceQuests(killer, func(o Objective) int {
+		if o.Kind == ObjectiveDefeat && o.Target == target {
+			return 1
+		}
+		return 0
+	})
+}
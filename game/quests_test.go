@@ -0,0 +1,142 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadQuestsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"valid", `[{"id":"a","objectives":[{"kind":"visit","biome":"forest"}]},
+			{"id":"b","prerequisites":["a"],"objectives":[{"kind":"defeat","target":"player","count":2}]}]`, ""},
+		{"no id", `[{"objectives":[{"kind":"visit","biome":"forest"}]}]`, "has no id"},
+		{"duplicate id", `[{"id":"a","objectives":[{"kind":"visit","biome":"forest"}]},{"id":"a","objectives":[{"kind":"visit","biome":"forest"}]}]`, "duplicate"},
+		{"unknown prerequisite", `[{"id":"a","prerequisites":["x"],"objectives":[{"kind":"visit","biome":"forest"}]}]`, "unknown prerequisite"},
+		{"no objectives", `[{"id":"a"}]`, "no objectives"},
+		{"visit nowhere", `[{"id":"a","objectives":[{"kind":"visit"}]}]`, "visit needs"},
+		{"location and biome", `[{"id":"a","objectives":[{"kind":"visit","location":"X","biome":"forest"}]}]`, "not both"},
+		{"unknown biome", `[{"id":"a","objectives":[{"kind":"visit","biome":"moon"}]}]`, "unknown biome"},
+		{"deliver nothing", `[{"id":"a","objectives":[{"kind":"deliver","biome":"forest"}]}]`, "deliver needs"},
+		{"bad defeat target", `[{"id":"a","objectives":[{"kind":"defeat","target":"dragon"}]}]`, "defeat target"},
+		{"unknown kind", `[{"id":"a","objectives":[{"kind":"dance"}]}]`, "unknown kind"},
+		{"unknown reward", `[{"id":"a","objectives":[{"kind":"visit","biome":"forest"}],"rewards":{"items":["Crown"]}}]`, "unknown reward"},
+		{"self prerequisite", `[{"id":"a","prerequisites":["a"],"objectives":[{"kind":"visit","biome":"forest"}]}]`, "cycle"},
+		{"prerequisite cycle", `[{"id":"a","prerequisites":["c"],"objectives":[{"kind":"visit","biome":"forest"}]},
+			{"id":"b","prerequisites":["a"],"objectives":[{"kind":"visit","biome":"forest"}]},
+			{"id":"c","prerequisites":["b"],"objectives":[{"kind":"visit","biome":"forest"}]}]`, "cycle"},
+		{"not JSON", `{`, "invalid quest file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadQuests(strings.NewReader(tt.json))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadQuests() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadQuests() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorldQuests(t *testing.T) {
+	locations := map[string]*Location{
+		"1": {ID: "1", Name: "Mossy Glade", Biome: BiomeForest},
+		"2": {ID: "2", Name: "Red Dunes", Biome: BiomeDesert},
+	}
+
+	tests := []struct {
+		name      string
+		objective Objective
+		want      bool
+	}{
+		{"named location present", Objective{Kind: ObjectiveVisit, Location: "Red Dunes"}, true},
+		{"named location missing", Objective{Kind: ObjectiveVisit, Location: "Nowhere"}, false},
+		{"biome present", Objective{Kind: ObjectiveVisit, Biome: BiomeForest}, true},
+		{"biome missing", Objective{Kind: ObjectiveVisit, Biome: BiomeTundra}, false},
+		{"no place needed", Objective{Kind: ObjectiveDefeat, Target: "player"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Quest{ID: "q", Objectives: []Objective{tt.objective}}
+			if got := len(worldQuests([]*Quest{q}, locations)) == 1; got != tt.want {
+				t.Errorf("kept = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuestArrivalByBiome(t *testing.T) {
+	g := newTestGame(t, Options{})
+	start := g.Locations[firstLocation(g)]
+	var loc *Location
+	for _, l := range g.Locations {
+		if l.Biome != start.Biome {
+			loc = l
+		}
+	}
+	if loc == nil {
+		t.Fatal("world has a single biome")
+	}
+	g.Quests = []*Quest{{ID: "q", Name: "Q", Objectives: []Objective{{Kind: ObjectiveVisit, Biome: loc.Biome}}}}
+	p := addTestPlayer(t, g, "a")
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	events := g.questArrival(p, loc)
+	if countEvents(events, EventQuestCompleted) != 1 {
+		t.Errorf("visiting a %s location did not complete the quest", loc.Biome)
+	}
+}
+
+func TestVisitObjectiveAtStart(t *testing.T) {
+	g := newTestGame(t, Options{})
+	loc := g.Locations[firstLocation(g)]
+	visit := []Objective{{Kind: ObjectiveVisit, Location: loc.Name}}
+	g.Quests = []*Quest{
+		{ID: "first", Name: "First", Objectives: visit, Rewards: Reward{Health: 50}},
+		{ID: "second", Name: "Second", Prerequisites: []string{"first"}, Objectives: visit},
+	}
+	p := &Player{ID: "a", Name: "a", CurrentLocation: loc.ID, Health: 90}
+	if err := g.AddPlayer(p, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	for _, q := range g.Quests {
+		if progress := p.quests[q.ID]; progress == nil || !progress.Completed {
+			t.Errorf("quest %s not completed by joining where it sends the player", q.ID)
+		}
+	}
+	if p.Health != g.Ruleset.StartingHealth {
+		t.Errorf("health = %d after the reward, want it capped at %d", p.Health, g.Ruleset.StartingHealth)
+	}
+}
+
+func TestDefeatObjectiveCountsOncePerDeath(t *testing.T) {
+	g := newTestGame(t, Options{})
+	g.Quests = []*Quest{{ID: "q", Name: "Q", Objectives: []Objective{{Kind: ObjectiveDefeat, Target: "player", Count: 2}}}}
+	killer := addTestPlayer(t, g, "killer")
+	victim := addTestPlayer(t, g, "victim")
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	g.startQuests(killer)
+	g.defeat(victim, "killer")
+	g.defeat(victim, "killer")
+	g.attackPlayer("killer", "victim", time.Now())
+
+	if got := killer.quests["q"].Progress[0]; got != 1 {
+		t.Errorf("progress = %d after one death, want 1", got)
+	}
+}
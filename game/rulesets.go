@@ -0,0 +1,34 @@
+package game
+
+import "fmt"
+
+// Ruleset bundles rules that set the tone of a game.
+type Ruleset struct {
+	Name           string `json:"name"`
+	StartingHealth int    `json:"starting_health"`
+	FriendlyFire   bool   `json:"friendly_fire"` // forces friendly fire on
+	SafeZones      bool   `json:"safe_zones"`
+	Hazards        bool   `json:"hazards"`
+}
+
+var Rulesets = map[string]Ruleset{
+	"standard": {Name: "standard", StartingHealth: 100, SafeZones: true, Hazards: true},
+	"hardcore": {Name: "hardcore", StartingHealth: 75, FriendlyFire: true, Hazards: true},
+	"casual":   {Name: "casual", StartingHealth: 150, SafeZones: true},
+}
+
+// DefaultRuleset is used when a game does not name one.
+const DefaultRuleset = "standard"
+
+// ParseRuleset looks up a ruleset by name. The empty string selects the
+// default.
+func ParseRuleset(name string) (Ruleset, error) {
+	if name == "" {
+		name = DefaultRuleset
+	}
+	ruleset, ok := Rulesets[name]
+	if !ok {
+		return Ruleset{}, fmt.Errorf("unknown ruleset: %s", name)
+	}
+	return ruleset, nil
+}
@@ -0,0 +1,36 @@
+package game
+
+// spectator is one spectator's event stream.
+type spectator struct {
+	id    string
+	admin bool // an administrator, who also sees concealed events
+}
+
+// AddSpectator registers a channel that receives every public event in the
+// game, wherever it happens. Spectators are not players: they have no
+// location and cannot act. Admin spectators also receive concealed events.
+// Remove the channel with RemoveClient.
+func (g *Game) AddSpectator(ch chan Event, spectatorID string, admin bool) {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+	g.spectators[ch] = spectator{id: spectatorID, admin: admin}
+}
+
+// SpectatorCount returns how many spectators are connected.
+func (g *Game) SpectatorCount() int {
+	g.ClientsMu.Lock()
+	defer g.ClientsMu.Unlock()
+	return len(g.spectators)
+}
+
+// canSee decides whether the spectator receives event. Spectators see global
+// events and events at every location, but not concealed events, so watching
+// reveals no more about hidden players than playing does. Only admins see
+// those. Messages addressed to particular players, such as whispers and
+// trades, reach no spectator.
+func (sp spectator) canSee(event Event) bool {
+	if len(event.Recipients) > 0 {
+		return false
+	}
+	return !event.Concealed || sp.admin
+}
@@ -0,0 +1,54 @@
+package game
+
+import "testing"
+
+func TestSpectatorCanSee(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       Event
+		want, admin bool
+	}{
+		{"located event", Event{Type: EventPlayerMoved, Location: "x"}, true, true},
+		{"global event", Event{Type: EventChatGlobal, Global: true}, true, true},
+		{"concealed event", Event{Type: EventHazardDamage, PlayerID: "a", Concealed: true}, false, true},
+		{"addressed event", Event{Type: EventChatWhisper, Recipients: []string{"a", "b"}}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (spectator{}).canSee(tt.event); got != tt.want {
+				t.Errorf("spectator sees it = %v, want %v", got, tt.want)
+			}
+			if got := (spectator{admin: true}).canSee(tt.event); got != tt.admin {
+				t.Errorf("admin spectator sees it = %v, want %v", got, tt.admin)
+			}
+		})
+	}
+}
+
+func TestSpectatorStream(t *testing.T) {
+	g := newTestGame(t, Options{})
+	ch := make(chan Event, 10)
+	g.AddSpectator(ch, "s1", false)
+	if got := g.SpectatorCount(); got != 1 {
+		t.Fatalf("SpectatorCount = %d, want 1", got)
+	}
+
+	g.BroadcastEvent(Event{Type: EventPlayerMoved, Location: "anywhere"})
+	g.BroadcastEvent(Event{Type: EventChatWhisper, Recipients: []string{"a"}})
+	g.BroadcastEvent(Event{Type: EventHazardDamage, PlayerID: "a", Concealed: true})
+	if got := len(ch); got != 1 {
+		t.Fatalf("spectator received %d events, want 1", got)
+	}
+	if e := <-ch; e.Type != EventPlayerMoved {
+		t.Errorf("received %s, want %s", e.Type, EventPlayerMoved)
+	}
+
+	g.RemoveClient(ch)
+	if got := g.SpectatorCount(); got != 0 {
+		t.Errorf("SpectatorCount after RemoveClient = %d, want 0", got)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after RemoveClient")
+	}
+}
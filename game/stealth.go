@@ -0,0 +1,229 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// AmbushBonus is the extra damage dealt by an attack from hiding.
+	AmbushBonus = 5
+
+	// perceptionDC is the base difficulty of spotting a hidden player. An
+	// observer rolls d20 + Dexterity against perceptionDC + the hider's
+	// Dexterity, so evenly matched players spot each other 30% of the time.
+	perceptionDC = 15
+	searchBonus  = 5 // added to the roll when actively searching
+	darkCover    = 3 // added to the difficulty at night and again in fog
+)
+
+// Hide conceals the player from everyone at their location. Players already
+// there get a chance to notice.
+func (g *Game) Hide(playerID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := g.beginAction(player, ActionHide, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.hide(player, now)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionHide, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// hide validates and resolves a hide attempt. The caller must hold g.Mu.
+func (g *Game) hide(player *Player, now time.Time) ([]Event, error) {
+	if player.Health <= 0 {
+		return nil, fmt.Errorf("player is defeated")
+	}
+	if player.Hidden {
+		return nil, fmt.Errorf("already hidden")
+	}
+	if player.Incapacitated() {
+		return nil, fmt.Errorf("player is stunned")
+	}
+	if len(g.opponents(player, now)) > 0 {
+		return nil, fmt.Errorf("cannot hide while engaged in combat")
+	}
+
+	player.Hidden = true
+	events := []Event{{
+		Type:      EventPlayerHidden,
+		PlayerID:  player.ID,
+		Location:  player.CurrentLocation,
+		Message:   fmt.Sprintf("%s slips into the shadows", player.Name),
+		Concealed: true,
+	}}
+	return append(events, g.perceptionChecks(player)...), nil
+}
+
+// Sneak moves a hidden player without being seen leaving or arriving.
+// Anyone at the destination gets a chance to notice them.
+func (g *Game) Sneak(playerID, locationID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := g.beginAction(player, ActionSneak, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.sneak(player, locationID)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionSneak, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// sneak validates and resolves a sneaking move. Hidden players are never
+// engaged, so there are no attacks of opportunity. The caller must hold g.Mu.
+func (g *Game) sneak(player *Player, locationID string) ([]Event, error) {
+	if !player.Hidden {
+		return nil, fmt.Errorf("must be hidden to sneak")
+	}
+	if err := g.checkMove(player, locationID); err != nil {
+		return nil, err
+	}
+	return g.relocate(player, locationID), nil
+}
+
+// Search makes an active perception check against every hidden player at
+// the searcher's location.
+func (g *Game) Search(playerID string) error {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	now := time.Now()
+	if err := g.beginAction(player, ActionSearch, now); err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	events, err := g.search(player)
+	if err == nil {
+		events = append(events, g.finishAction(player, ActionSearch, now)...)
+	}
+
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return err
+}
+
+// search resolves a search. Finding nobody is reported to the searcher
+// alone. The caller must hold g.Mu.
+func (g *Game) search(player *Player) ([]Event, error) {
+	if player.Health <= 0 {
+		return nil, fmt.Errorf("player is defeated")
+	}
+
+	var events []Event
+	for _, other := range g.livingAt(player.CurrentLocation) {
+		if other.Hidden && other.ID != player.ID && g.perceives(player, other, searchBonus) {
+			events = append(events, g.reveal(other, player)...)
+		}
+	}
+
+	if len(events) == 0 {
+		events = append(events, Event{
+			Type:      EventSearchFailed,
+			PlayerID:  player.ID,
+			Location:  player.CurrentLocation,
+			Message:   fmt.Sprintf("%s searches the area but finds no one", player.Name),
+			Concealed: true,
+		})
+	}
+	return events, nil
+}
+
+// perceptionChecks gives players who share a location a passive chance to
+// notice each other after player hides or arrives. If player is hidden,
+// everyone else there tries to spot them; otherwise player tries to spot
+// anyone hidden there. The caller must hold g.Mu.
+func (g *Game) perceptionChecks(player *Player) []Event {
+	var events []Event
+	for _, other := range g.livingAt(player.CurrentLocation) {
+		if other.ID == player.ID {
+			continue
+		}
+		if player.Hidden && !other.Hidden && g.perceives(other, player, 0) {
+			return g.reveal(player, other)
+		}
+		if !player.Hidden && other.Hidden && g.perceives(player, other, 0) {
+			events = append(events, g.reveal(other, player)...)
+		}
+	}
+	return events
+}
+
+// perceives rolls observer's perception against hider's stealth. The caller
+// must hold g.Mu.
+func (g *Game) perceives(observer, hider *Player, bonus int) bool {
+	difficulty := perceptionDC + hider.EffectiveDexterity()
+	if g.clock.Phase() == PhaseNight {
+		difficulty += darkCover
+	}
+	if g.clock.weather == WeatherFog {
+		difficulty += darkCover
+	}
+	return rand.Intn(20)+1+observer.EffectiveDexterity()+bonus >= difficulty
+}
+
+// reveal ends player's concealment. spotter is whoever noticed them, or nil
+// if they gave themselves away. The caller must hold g.Mu.
+func (g *Game) reveal(player *Player, spotter *Player) []Event {
+	player.Hidden = false
+
+	event := Event{
+		Type:     EventPlayerRevealed,
+		PlayerID: player.ID,
+		Location: player.CurrentLocation,
+		Message:  fmt.Sprintf("%s steps out of the shadows", player.Name),
+	}
+	if spotter != nil {
+		event.TargetID = spotter.ID
+		event.Message = fmt.Sprintf("%s spots %s hiding", spotter.Name, player.Name)
+	}
+	return []Event{event}
+}
+
+// livingAt returns the living players at location, ordered by ID so that
+// checks resolve in a stable order. The caller must hold g.Mu.
+func (g *Game) livingAt(location string) []*Player {
+	var players []*Player
+	for _, p := range g.Players {
+		if p.CurrentLocation == location && p.Health > 0 {
+			players = append(players, p)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
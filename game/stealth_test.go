@@ -0,0 +1,53 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHiddenPlayersEventsAreConcealed(t *testing.T) {
+	tests := []struct {
+		name   string
+		hidden bool
+	}{
+		{"visible player", false},
+		{"hidden player", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{})
+			p := addTestPlayer(t, g, "a")
+			addTestPlayer(t, g, "b")
+			p.Hidden = tt.hidden
+			g.Locations[p.CurrentLocation].Hazard = &Hazard{Kind: "acid", Damage: 1, Effect: EffectPoison}
+
+			now := time.Now()
+			events := g.tickHazards(now)
+			for _, e := range p.Effects {
+				e.ExpiresAt = now
+			}
+			events = append(events, g.tickEffects(now)...)
+
+			for _, typ := range []EventType{EventHazardDamage, EventEffectApplied, EventEffectTick, EventEffectExpired} {
+				if countEvents(events, typ) == 0 {
+					t.Fatalf("no %s event", typ)
+				}
+			}
+			for _, e := range events {
+				if e.PlayerID != p.ID {
+					continue // b stands in the hazard too
+				}
+				if e.Concealed != tt.hidden {
+					t.Errorf("%s event Concealed = %v, want %v", e.Type, e.Concealed, tt.hidden)
+				}
+				if got := canSee(e, "b", p.CurrentLocation); got == tt.hidden {
+					t.Errorf("%s event visible to b = %v, want %v", e.Type, got, !tt.hidden)
+				}
+				if !canSee(e, p.ID, p.CurrentLocation) {
+					t.Errorf("%s event not visible to its own player", e.Type)
+				}
+			}
+		})
+	}
+}
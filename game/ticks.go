@@ -0,0 +1,229 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultTickWindow is how long simultaneous games collect commands before
+// resolving them together.
+const DefaultTickWindow = 2 * time.Second
+
+// Command is an action queued for resolution at the next tick boundary.
+type Command struct {
+	PlayerID string    `json:"player_id"`
+	Action   Action    `json:"action"`
+	Target   string    `json:"target"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+type tickState struct {
+	number    int
+	resolveAt time.Time
+	pending   map[string]*Command // by player ID; a newer command replaces an older one
+}
+
+// TickStatus describes the current tick window of a simultaneous game.
+type TickStatus struct {
+	Tick      int       `json:"tick"`
+	ResolveAt time.Time `json:"resolve_at"`
+	Queued    *Command  `json:"queued,omitempty"`
+}
+
+// TickStatus returns the current tick window and the player's queued command,
+// or nil if the game is not simultaneous.
+func (g *Game) TickStatus(playerID string) *TickStatus {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	if g.Mode != ModeSimultaneous {
+		return nil
+	}
+
+	status := &TickStatus{Tick: g.ticks.number, ResolveAt: g.ticks.resolveAt}
+	if cmd := g.ticks.pending[playerID]; cmd != nil {
+		queued := *cmd
+		status.Queued = &queued
+	}
+	return status
+}
+
+// QueueAction records a command for the next tick boundary and returns when
+// it will be resolved. Only the player's latest command is kept.
+func (g *Game) QueueAction(playerID string, action Action, target string) (time.Time, error) {
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+
+	if g.Mode != ModeSimultaneous {
+		return time.Time{}, fmt.Errorf("actions are only queued in simultaneous games")
+	}
+
+	player := g.Players[playerID]
+	if player == nil {
+		return time.Time{}, fmt.Errorf("player not found")
+	}
+	if player.Health <= 0 {
+		return time.Time{}, fmt.Errorf("player is defeated")
+	}
+
+	switch action {
+	case ActionMove, ActionSneak:
+		if g.Locations[target] == nil {
+			return time.Time{}, fmt.Errorf("location not found")
+		}
+	case ActionHide, ActionSearch:
+	case ActionFlee:
+		if target != "" && g.Locations[target] == nil {
+			return time.Time{}, fmt.Errorf("location not found")
+		}
+	case ActionAttack:
+		if g.Players[target] == nil {
+			return time.Time{}, fmt.Errorf("player not found")
+		}
+	case ActionUse:
+		if player.findItem(target) < 0 {
+			return time.Time{}, fmt.Errorf("item not in inventory")
+		}
+	default:
+		return time.Time{}, fmt.Errorf("%s cannot be queued", action)
+	}
+
+	g.ticks.pending[playerID] = &Command{
+		PlayerID: playerID,
+		Action:   action,
+		Target:   target,
+		QueuedAt: time.Now(),
+	}
+	return g.ticks.resolveAt, nil
+}
+
+// commandOrder sorts commands into resolution order: every move, flee,
+// sneak, hide and item use resolves before any search, and searches before any
+// attack, so a player who moves away or hides escapes attacks queued in the
+// same tick. Attacks then land in order of effective Dexterity. Player ID
+// breaks all remaining ties. The caller must hold g.Mu.
+func (g *Game) commandOrder(commands []*Command) {
+	rank := func(a Action) int {
+		switch a {
+		case ActionMove, ActionFlee, ActionSneak, ActionHide, ActionUse:
+			return 0
+		case ActionSearch:
+			return 1
+		}
+		return 2
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		ci, cj := commands[i], commands[j]
+		if rank(ci.Action) != rank(cj.Action) {
+			return rank(ci.Action) < rank(cj.Action)
+		}
+		if ci.Action == ActionAttack {
+			di := g.Players[ci.PlayerID].EffectiveDexterity()
+			dj := g.Players[cj.PlayerID].EffectiveDexterity()
+			if di != dj {
+				return di > dj
+			}
+		}
+		return ci.PlayerID < cj.PlayerID
+	})
+}
+
+// resolveTick resolves every queued command and returns one batched event
+// per location that saw activity. Commands start no cooldowns, so weather
+// that lengthens cooldowns does not affect them. The caller must hold g.Mu.
+func (g *Game) resolveTick(now time.Time) []Event {
+	commands := make([]*Command, 0, len(g.ticks.pending))
+	for _, cmd := range g.ticks.pending {
+		if g.Players[cmd.PlayerID] != nil {
+			commands = append(commands, cmd)
+		}
+	}
+	g.commandOrder(commands)
+
+	var results []Event
+	for _, cmd := range commands {
+		player := g.Players[cmd.PlayerID]
+		if player.Health <= 0 {
+			results = append(results, Event{
+				Type:     EventCommandFailed,
+				PlayerID: player.ID,
+				Location: player.CurrentLocation,
+				Message:  fmt.Sprintf("%s was defeated before they could %s", player.Name, cmd.Action),
+			})
+			continue
+		}
+
+		var events []Event
+		var err error
+		switch cmd.Action {
+		case ActionMove:
+			events, err = g.movePlayer(cmd.PlayerID, cmd.Target, now)
+		case ActionAttack:
+			events, err = g.attackPlayer(cmd.PlayerID, cmd.Target, now)
+		case ActionFlee:
+			events, err = g.flee(cmd.PlayerID, cmd.Target, now)
+		case ActionHide:
+			events, err = g.hide(player, now)
+		case ActionSneak:
+			events, err = g.sneak(player, cmd.Target)
+		case ActionSearch:
+			events, err = g.search(player)
+		case ActionUse:
+			events, err = g.useItem(player, cmd.Target, now)
+		}
+
+		results = append(results, events...)
+		if err != nil {
+			results = append(results, Event{
+				Type:     EventCommandFailed,
+				PlayerID: player.ID,
+				TargetID: cmd.Target,
+				Location: player.CurrentLocation,
+				Message:  fmt.Sprintf("%s could not %s: %v", player.Name, cmd.Action, err),
+			})
+			continue
+		}
+	}
+
+	tick := g.ticks.number
+	g.ticks.number++
+	g.ticks.pending = make(map[string]*Command)
+	g.ticks.resolveAt = now.Add(g.TickWindow)
+
+	return batchByLocation(tick, results, now)
+}
+
+// batchByLocation groups events into one tick_resolved event per location,
+// keeping resolution order within each batch. Events meant for particular
+// players are passed through unbatched so the batch does not leak them, and
+// so are global events and events seen from several locations, which a
+// single location's batch would hide from everyone else.
+func batchByLocation(tick int, events []Event, now time.Time) []Event {
+	var locations []string
+	var unbatched []Event
+	byLocation := make(map[string][]Event)
+	for _, e := range events {
+		e.Timestamp = now
+		if len(e.Recipients) > 0 || e.Concealed || e.Global || len(e.Locations) > 0 {
+			unbatched = append(unbatched, e)
+			continue
+		}
+		if _, seen := byLocation[e.Location]; !seen {
+			locations = append(locations, e.Location)
+		}
+		byLocation[e.Location] = append(byLocation[e.Location], e)
+	}
+
+	batches := make([]Event, 0, len(locations))
+	for _, loc := range locations {
+		batches = append(batches, Event{
+			Type:     EventTickResolved,
+			Location: loc,
+			Message:  fmt.Sprintf("Tick %d resolved", tick),
+			Events:   byLocation[loc],
+		})
+	}
+	return append(batches, unbatched...)
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    Mode
+		action  Action
+		target  func(g *Game) string
+		defeat  bool
+		wantErr bool
+	}{
+		{"move", ModeSimultaneous, ActionMove, func(g *Game) string { return firstLocation(g) }, false, false},
+		{"attack", ModeSimultaneous, ActionAttack, func(*Game) string { return "b" }, false, false},
+		{"not simultaneous", ModeRealTime, ActionMove, func(g *Game) string { return firstLocation(g) }, false, true},
+		{"unknown location", ModeSimultaneous, ActionMove, func(*Game) string { return "nowhere" }, false, true},
+		{"unknown target", ModeSimultaneous, ActionAttack, func(*Game) string { return "nobody" }, false, true},
+		{"item not held", ModeSimultaneous, ActionUse, func(*Game) string { return "nothing" }, false, true},
+		{"not queueable", ModeSimultaneous, ActionChat, func(*Game) string { return "" }, false, true},
+		{"defeated", ModeSimultaneous, ActionHide, func(*Game) string { return "" }, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{Mode: tt.mode})
+			a := addTestPlayer(t, g, "a")
+			addTestPlayer(t, g, "b")
+			if tt.defeat {
+				a.Health = 0
+			}
+
+			_, err := g.QueueAction("a", tt.action, tt.target(g))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QueueAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status := g.TickStatus("a"); !tt.wantErr && (status == nil || status.Queued == nil || status.Queued.Action != tt.action) {
+				t.Errorf("queued command = %+v, want %s", status, tt.action)
+			}
+		})
+	}
+}
+
+func TestCommandOrder(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeSimultaneous})
+	for _, id := range []string{"slow", "fast", "mover", "searcher"} {
+		addTestPlayer(t, g, id)
+	}
+	g.Players["fast"].Dexterity = 18
+
+	commands := []*Command{
+		{PlayerID: "slow", Action: ActionAttack},
+		{PlayerID: "searcher", Action: ActionSearch},
+		{PlayerID: "fast", Action: ActionAttack},
+		{PlayerID: "mover", Action: ActionMove},
+	}
+	g.commandOrder(commands)
+
+	want := []string{"mover", "searcher", "fast", "slow"}
+	for i, cmd := range commands {
+		if cmd.PlayerID != want[i] {
+			t.Fatalf("position %d = %s, want %s", i, cmd.PlayerID, want[i])
+		}
+	}
+}
+
+func TestResolveTickMoveEscapesAttack(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeSimultaneous})
+	addTestPlayer(t, g, "attacker")
+	target := addTestPlayer(t, g, "target")
+	away := openNeighbor(t, g)
+
+	if _, err := g.QueueAction("attacker", ActionAttack, "target"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.QueueAction("target", ActionMove, away); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	events := g.resolveTick(time.Now())
+
+	if target.CurrentLocation != away || target.Health != g.Ruleset.StartingHealth {
+		t.Errorf("target at %s with %d health, want unharmed at %s", target.CurrentLocation, target.Health, away)
+	}
+	if countEvents(events, EventCommandFailed) != 1 {
+		t.Errorf("want the attack reported as failed, got %+v", events)
+	}
+	if len(g.ticks.pending) != 0 || g.ticks.number != 1 {
+		t.Errorf("tick %d left %d commands pending", g.ticks.number, len(g.ticks.pending))
+	}
+}
+
+func TestResolveTickGlobalEventsReachEveryone(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeSimultaneous, Teams: []string{"red", "blue"}, ScoreLimit: 1})
+	a := addTestPlayer(t, g, "a")
+	b := addTestPlayer(t, g, "b")
+	c := addTestPlayer(t, g, "c")
+	a.Team, b.Team = "red", "blue"
+	b.Health = 1
+	c.CurrentLocation = openNeighbor(t, g)
+
+	if _, err := g.QueueAction("a", ActionAttack, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	events := g.resolveTick(time.Now())
+
+	seen := false
+	for _, e := range events {
+		if e.Type == EventTeamWon {
+			seen = canSee(e, c.ID, c.CurrentLocation)
+		}
+	}
+	if !seen {
+		t.Errorf("team win not visible to a player elsewhere: %+v", events)
+	}
+}
+
+func TestResolveTickIgnoresStorms(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeSimultaneous})
+	p := addTestPlayer(t, g, "a")
+	away := openNeighbor(t, g)
+	g.clock.weather = WeatherStorm
+
+	if _, err := g.QueueAction("a", ActionMove, away); err != nil {
+		t.Fatal(err)
+	}
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	g.resolveTick(time.Now())
+
+	if p.CurrentLocation != away {
+		t.Errorf("player at %s, want %s", p.CurrentLocation, away)
+	}
+	if len(p.cooldowns) != 0 {
+		t.Errorf("queued move started cooldowns %v", p.cooldowns)
+	}
+}
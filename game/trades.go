@@ -0,0 +1,272 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"game-api/utils"
+)
+
+// TradeTimeout is how long a trade stays open without any activity.
+const TradeTimeout = 2 * time.Minute
+
+// Trade is an exchange being negotiated between two players. Items only
+// change hands when both sides have confirmed the current offers.
+type Trade struct {
+	ID        string              `json:"id"`
+	Players   [2]string           `json:"players"`
+	Offers    map[string][]string `json:"offers"` // player ID -> offered item IDs
+	Confirmed map[string]bool     `json:"confirmed"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+func (t *Trade) partner(playerID string) string {
+	if t.Players[0] == playerID {
+		return t.Players[1]
+	}
+	return t.Players[0]
+}
+
+// tradeEvent builds an event only the two traders can see.
+func tradeEvent(t *Trade, eventType EventType, playerID, message string) Event {
+	return Event{
+		Type:       eventType,
+		PlayerID:   playerID,
+		TargetID:   t.ID,
+		Message:    message,
+		Recipients: []string{t.Players[0], t.Players[1]},
+	}
+}
+
+// GetTrade returns a copy of the player's open trade, or nil.
+func (g *Game) GetTrade(playerID string) *Trade {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	player := g.Players[playerID]
+	if player == nil || g.trades[player.tradeID] == nil {
+		return nil
+	}
+
+	t := g.trades[player.tradeID]
+	trade := &Trade{
+		ID:        t.ID,
+		Players:   t.Players,
+		Offers:    make(map[string][]string),
+		Confirmed: make(map[string]bool),
+		ExpiresAt: t.ExpiresAt,
+	}
+	for id, offer := range t.Offers {
+		trade.Offers[id] = append([]string(nil), offer...)
+	}
+	for id, ok := range t.Confirmed {
+		trade.Confirmed[id] = ok
+	}
+	return trade
+}
+
+// OpenTrade starts a trade between two co-located players.
+func (g *Game) OpenTrade(playerID, partnerID string) (string, error) {
+	g.Mu.Lock()
+	player := g.Players[playerID]
+	partner := g.Players[partnerID]
+	if player == nil || partner == nil {
+		g.Mu.Unlock()
+		return "", fmt.Errorf("player not found")
+	}
+	if playerID == partnerID {
+		g.Mu.Unlock()
+		return "", fmt.Errorf("cannot trade with yourself")
+	}
+	if player.Health <= 0 || partner.Health <= 0 {
+		g.Mu.Unlock()
+		return "", fmt.Errorf("defeated players cannot trade")
+	}
+	if player.CurrentLocation != partner.CurrentLocation || !CanSeePlayer(playerID, partner) {
+		g.Mu.Unlock()
+		return "", fmt.Errorf("players not in same location")
+	}
+	if player.tradeID != "" || partner.tradeID != "" {
+		g.Mu.Unlock()
+		return "", fmt.Errorf("a player is already trading")
+	}
+
+	trade := &Trade{
+		ID:        utils.GenerateID(8),
+		Players:   [2]string{playerID, partnerID},
+		Offers:    map[string][]string{playerID: {}, partnerID: {}},
+		Confirmed: map[string]bool{playerID: false, partnerID: false},
+		ExpiresAt: time.Now().Add(TradeTimeout),
+	}
+	g.trades[trade.ID] = trade
+	player.tradeID = trade.ID
+	partner.tradeID = trade.ID
+
+	event := tradeEvent(trade, EventTradeOpened, playerID,
+		fmt.Sprintf("%s wants to trade with %s", player.Name, partner.Name))
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(event)
+	return trade.ID, nil
+}
+
+// playerTrade looks up the open trade tradeID for playerID. The caller must
+// hold g.Mu.
+func (g *Game) playerTrade(playerID, tradeID string) (*Player, *Trade, error) {
+	player := g.Players[playerID]
+	if player == nil {
+		return nil, nil, fmt.Errorf("player not found")
+	}
+	trade := g.trades[tradeID]
+	if trade == nil || player.tradeID != tradeID {
+		return nil, nil, fmt.Errorf("trade not found")
+	}
+	return player, trade, nil
+}
+
+// OfferItems replaces the player's side of the trade. Any change to an offer
+// withdraws both confirmations.
+func (g *Game) OfferItems(playerID, tradeID string, itemIDs []string) error {
+	g.Mu.Lock()
+	player, trade, err := g.playerTrade(playerID, tradeID)
+	if err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range itemIDs {
+		if seen[id] {
+			g.Mu.Unlock()
+			return fmt.Errorf("item %s offered twice", id)
+		}
+		if player.findItem(id) < 0 {
+			g.Mu.Unlock()
+			return fmt.Errorf("you do not have item %s", id)
+		}
+		seen[id] = true
+	}
+
+	trade.Offers[playerID] = append([]string{}, itemIDs...)
+	for id := range trade.Confirmed {
+		trade.Confirmed[id] = false
+	}
+	trade.ExpiresAt = time.Now().Add(TradeTimeout)
+
+	event := tradeEvent(trade, EventTradeUpdated, playerID,
+		fmt.Sprintf("%s offers %d item(s)", player.Name, len(itemIDs)))
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(event)
+	return nil
+}
+
+// ConfirmTrade accepts the current offers. Once both players have confirmed,
+// the items are exchanged.
+func (g *Game) ConfirmTrade(playerID, tradeID string) error {
+	g.Mu.Lock()
+	player, trade, err := g.playerTrade(playerID, tradeID)
+	if err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	trade.Confirmed[playerID] = true
+	trade.ExpiresAt = time.Now().Add(TradeTimeout)
+	events := []Event{tradeEvent(trade, EventTradeUpdated, playerID,
+		fmt.Sprintf("%s confirmed the trade", player.Name))}
+
+	if trade.Confirmed[trade.Players[0]] && trade.Confirmed[trade.Players[1]] {
+		events = append(events, g.executeTrade(trade)...)
+	}
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return nil
+}
+
+// CancelTrade abandons the trade without exchanging anything.
+func (g *Game) CancelTrade(playerID, tradeID string) error {
+	g.Mu.Lock()
+	player, trade, err := g.playerTrade(playerID, tradeID)
+	if err != nil {
+		g.Mu.Unlock()
+		return err
+	}
+
+	event := g.closeTrade(trade, EventTradeCancelled, fmt.Sprintf("%s cancelled the trade", player.Name))
+	g.Mu.Unlock()
+
+	g.BroadcastEvent(event)
+	return nil
+}
+
+// executeTrade swaps the offered items. Every precondition is rechecked and
+// nothing moves unless all of them hold, so the exchange either happens in
+// full or not at all. The caller must hold g.Mu.
+func (g *Game) executeTrade(trade *Trade) []Event {
+	a, b := g.Players[trade.Players[0]], g.Players[trade.Players[1]]
+	switch {
+	case a == nil || b == nil:
+		return []Event{g.closeTrade(trade, EventTradeCancelled, "Trade cancelled: a player left the game")}
+	case a.Health <= 0 || b.Health <= 0:
+		return []Event{g.closeTrade(trade, EventTradeCancelled, "Trade cancelled: a player was defeated")}
+	case a.CurrentLocation != b.CurrentLocation:
+		return []Event{g.closeTrade(trade, EventTradeCancelled, "Trade cancelled: players are no longer together")}
+	}
+
+	for _, p := range []*Player{a, b} {
+		for _, id := range trade.Offers[p.ID] {
+			if p.findItem(id) < 0 {
+				return []Event{g.closeTrade(trade, EventTradeCancelled, "Trade cancelled: an offered item is gone")}
+			}
+		}
+	}
+
+	var toB, toA []*Item
+	for _, id := range trade.Offers[a.ID] {
+		toB = append(toB, a.takeItem(id))
+	}
+	for _, id := range trade.Offers[b.ID] {
+		toA = append(toA, b.takeItem(id))
+	}
+	a.Inventory = append(a.Inventory, toA...)
+	b.Inventory = append(b.Inventory, toB...)
+
+	return []Event{g.closeTrade(trade, EventTradeCompleted,
+		fmt.Sprintf("%s and %s completed a trade", a.Name, b.Name))}
+}
+
+// closeTrade removes the trade and returns the event announcing why. The
+// caller must hold g.Mu.
+func (g *Game) closeTrade(trade *Trade, eventType EventType, message string) Event {
+	delete(g.trades, trade.ID)
+	for _, id := range trade.Players {
+		if p := g.Players[id]; p != nil && p.tradeID == trade.ID {
+			p.tradeID = ""
+		}
+	}
+	return tradeEvent(trade, eventType, "", message)
+}
+
+// cancelTrades aborts any trade the player is part of. Used when a player is
+// defeated or removed. The caller must hold g.Mu.
+func (g *Game) cancelTrades(player *Player, reason string) []Event {
+	trade := g.trades[player.tradeID]
+	if trade == nil {
+		return nil
+	}
+	return []Event{g.closeTrade(trade, EventTradeCancelled, "Trade cancelled: "+reason)}
+}
+
+// expireTrades closes trades that have been idle too long. The caller must
+// hold g.Mu.
+func (g *Game) expireTrades(now time.Time) []Event {
+	var events []Event
+	for _, trade := range g.trades {
+		if now.After(trade.ExpiresAt) {
+			events = append(events, g.closeTrade(trade, EventTradeCancelled, "Trade timed out"))
+		}
+	}
+	return events
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// tradingPair is two players at the same location holding one item each.
+func tradingPair(t *testing.T) (*Game, *Player, *Player) {
+	t.Helper()
+	g := newTestGame(t, Options{})
+	a := addTestPlayer(t, g, "a")
+	b := addTestPlayer(t, g, "b")
+	a.Inventory = []*Item{NewItem("Torch")}
+	b.Inventory = []*Item{NewItem("Rope")}
+	return g, a, b
+}
+
+func TestOpenTradeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		partner string
+		prepare func(g *Game, a, b *Player)
+	}{
+		{"unknown partner", "nobody", func(*Game, *Player, *Player) {}},
+		{"yourself", "a", func(*Game, *Player, *Player) {}},
+		{"partner defeated", "b", func(_ *Game, _, b *Player) { b.Health = 0 }},
+		{"apart", "b", func(_ *Game, _, b *Player) { b.CurrentLocation = "elsewhere" }},
+		{"already trading", "b", func(g *Game, _, _ *Player) {
+			addTestPlayer(t, g, "c")
+			g.OpenTrade("b", "c")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, a, b := tradingPair(t)
+			tt.prepare(g, a, b)
+
+			if _, err := g.OpenTrade("a", tt.partner); err == nil {
+				t.Error("OpenTrade() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestOfferItemsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		items func(a, b *Player) []string
+	}{
+		{"offered twice", func(a, _ *Player) []string { return []string{a.Inventory[0].ID, a.Inventory[0].ID} }},
+		{"not held", func(_, b *Player) []string { return []string{b.Inventory[0].ID} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, a, b := tradingPair(t)
+			tradeID, err := g.OpenTrade("a", "b")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.OfferItems("a", tradeID, tt.items(a, b)); err == nil {
+				t.Error("OfferItems() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestTradeExchange(t *testing.T) {
+	g, a, b := tradingPair(t)
+	torch, rope := a.Inventory[0], b.Inventory[0]
+
+	tradeID, err := g.OpenTrade("a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []func() error{
+		func() error { return g.OfferItems("a", tradeID, []string{torch.ID}) },
+		func() error { return g.ConfirmTrade("a", tradeID) },
+		// Changing an offer withdraws the confirmation above
+		func() error { return g.OfferItems("b", tradeID, []string{rope.ID}) },
+		func() error { return g.ConfirmTrade("b", tradeID) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	if a.findItem(torch.ID) < 0 {
+		t.Fatal("items moved before both sides confirmed the final offers")
+	}
+
+	if err := g.ConfirmTrade("a", tradeID); err != nil {
+		t.Fatal(err)
+	}
+	if a.findItem(rope.ID) < 0 || b.findItem(torch.ID) < 0 || a.findItem(torch.ID) >= 0 {
+		t.Errorf("items not exchanged: a=%v b=%v", a.Inventory, b.Inventory)
+	}
+	if g.GetTrade("a") != nil {
+		t.Error("completed trade is still open")
+	}
+}
+
+func TestTradeIsAtomic(t *testing.T) {
+	g, a, b := tradingPair(t)
+	torch, rope := a.Inventory[0], b.Inventory[0]
+
+	tradeID, _ := g.OpenTrade("a", "b")
+	g.OfferItems("a", tradeID, []string{torch.ID})
+	g.OfferItems("b", tradeID, []string{rope.ID})
+	g.ConfirmTrade("a", tradeID)
+
+	// The torch is used up before the trade completes
+	g.Mu.Lock()
+	a.takeItem(torch.ID)
+	g.Mu.Unlock()
+	g.ConfirmTrade("b", tradeID)
+
+	if b.findItem(rope.ID) < 0 || a.findItem(rope.ID) >= 0 {
+		t.Error("half of a failed trade went through")
+	}
+	if g.GetTrade("b") != nil {
+		t.Error("failed trade is still open")
+	}
+}
+
+func TestExpireTrades(t *testing.T) {
+	g, _, _ := tradingPair(t)
+	if _, err := g.OpenTrade("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Mu.Lock()
+	defer g.Mu.Unlock()
+	if events := g.expireTrades(time.Now()); len(events) != 0 {
+		t.Errorf("a fresh trade expired")
+	}
+	if events := g.expireTrades(time.Now().Add(TradeTimeout + time.Second)); countEvents(events, EventTradeCancelled) != 1 {
+		t.Errorf("an idle trade did not expire")
+	}
+}
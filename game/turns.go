@@ -0,0 +1,194 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTurnTimeout is how long a player has to act before their turn is
+// passed automatically.
+const DefaultTurnTimeout = 30 * time.Second
+
+type turnState struct {
+	order    []string // player IDs in initiative order
+	index    int
+	round    int
+	deadline time.Time
+}
+
+func (t *turnState) activePlayerID() string {
+	if t.index < 0 || t.index >= len(t.order) {
+		return ""
+	}
+	return t.order[t.index]
+}
+
+// TurnStatus describes the current turn of a turn-based game.
+type TurnStatus struct {
+	Round          int       `json:"round"`
+	ActivePlayerID string    `json:"active_player_id,omitempty"`
+	Order          []string  `json:"order"`
+	Deadline       time.Time `json:"deadline"`
+}
+
+// TurnStatus returns the current turn, or nil if the game is not turn-based.
+func (g *Game) TurnStatus() *TurnStatus {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	if g.Mode != ModeTurnBased {
+		return nil
+	}
+
+	return &TurnStatus{
+		Round:          g.turns.round,
+		ActivePlayerID: g.turns.activePlayerID(),
+		Order:          append([]string(nil), g.turns.order...),
+		Deadline:       g.turns.deadline,
+	}
+}
+
+// PassTurn ends the player's turn without acting.
+func (g *Game) PassTurn(playerID string) error {
+	g.Mu.Lock()
+	if g.Mode != ModeTurnBased {
+		g.Mu.Unlock()
+		return fmt.Errorf("passing is only possible in turn-based games")
+	}
+
+	player := g.Players[playerID]
+	if player == nil {
+		g.Mu.Unlock()
+		return fmt.Errorf("player not found")
+	}
+
+	if g.turns.activePlayerID() != playerID {
+		g.Mu.Unlock()
+		return fmt.Errorf("not your turn")
+	}
+
+	events := g.endTurn(time.Now(), fmt.Sprintf("%s passed", player.Name))
+	g.Mu.Unlock()
+
+	g.broadcastEvents(events)
+	return nil
+}
+
+// rollInitiative orders the living players for a new round. Each player
+// rolls a d20 and adds their Dexterity; ties go to the higher Dexterity.
+// The caller must hold g.Mu.
+func (g *Game) rollInitiative() []string {
+	type roll struct {
+		id    string
+		dex   int
+		score int
+	}
+
+	rolls := make([]roll, 0, len(g.Players))
+	for _, p := range g.Players {
+		if p.Health <= 0 {
+			continue
+		}
+		dex := p.EffectiveDexterity()
+		rolls = append(rolls, roll{id: p.ID, dex: dex, score: dex + rand.Intn(20) + 1})
+	}
+
+	sort.Slice(rolls, func(i, j int) bool {
+		if rolls[i].score != rolls[j].score {
+			return rolls[i].score > rolls[j].score
+		}
+		if rolls[i].dex != rolls[j].dex {
+			return rolls[i].dex > rolls[j].dex
+		}
+		return rolls[i].id < rolls[j].id
+	})
+
+	order := make([]string, len(rolls))
+	for i, r := range rolls {
+		order[i] = r.id
+	}
+	return order
+}
+
+// startTurn hands the turn to the next eligible player in the order,
+// rolling a new round when the order is exhausted. The caller must hold
+// g.Mu.
+func (g *Game) startTurn(now time.Time) []Event {
+	var events []Event
+
+	for {
+		if g.turns.index >= len(g.turns.order) {
+			order := g.rollInitiative()
+			if len(order) == 0 {
+				g.turns = turnState{round: g.turns.round}
+				return events
+			}
+
+			g.turns.order = order
+			g.turns.index = 0
+			g.turns.round++
+
+			names := make([]string, len(order))
+			for i, id := range order {
+				names[i] = g.Players[id].Name
+			}
+			events = append(events, Event{
+				Type:    EventRoundStarted,
+				Message: fmt.Sprintf("Round %d initiative: %s", g.turns.round, strings.Join(names, ", ")),
+				Global:  true,
+			})
+		}
+
+		// Skip players who left or were defeated since initiative was rolled
+		player := g.Players[g.turns.activePlayerID()]
+		if player == nil || player.Health <= 0 {
+			g.turns.index++
+			continue
+		}
+
+		g.turns.deadline = now.Add(g.TurnTimeout)
+		return append(events, Event{
+			Type:     EventTurnStarted,
+			PlayerID: player.ID,
+			Message:  fmt.Sprintf("It is %s's turn", player.Name),
+			Global:   true,
+		})
+	}
+}
+
+// endTurn closes the active player's turn and starts the next one. The
+// caller must hold g.Mu.
+func (g *Game) endTurn(now time.Time, reason string) []Event {
+	events := []Event{{
+		Type:     EventTurnEnded,
+		PlayerID: g.turns.activePlayerID(),
+		Message:  reason,
+		Global:   true,
+	}}
+
+	g.turns.index++
+	return append(events, g.startTurn(now)...)
+}
+
+// tickTurns starts the first turn once players are present and passes turns
+// that have timed out or whose player cannot act. The caller must hold g.Mu.
+func (g *Game) tickTurns(now time.Time) []Event {
+	activeID := g.turns.activePlayerID()
+	if activeID == "" {
+		return g.startTurn(now)
+	}
+
+	player := g.Players[activeID]
+	switch {
+	case player == nil || player.Health <= 0:
+		return g.endTurn(now, "Turn skipped")
+	case player.Incapacitated():
+		return g.endTurn(now, fmt.Sprintf("%s is stunned and loses their turn", player.Name))
+	case !now.Before(g.turns.deadline):
+		return g.endTurn(now, fmt.Sprintf("%s ran out of time", player.Name))
+	}
+	return nil
+}
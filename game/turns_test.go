@@ -0,0 +1,92 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPassTurn(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeTurnBased})
+	for _, id := range []string{"a", "b", "c"} {
+		addTestPlayer(t, g, id)
+	}
+
+	// The first player to join starts round 1 alone; everyone rolls
+	// initiative for round 2
+	if err := g.PassTurn("a"); err != nil {
+		t.Fatalf("PassTurn(a): %v", err)
+	}
+	order := g.TurnStatus().Order
+	if len(order) != 3 {
+		t.Fatalf("initiative order = %v, want 3 players", order)
+	}
+
+	tests := []struct {
+		name     string
+		playerID string
+		wantErr  bool
+	}{
+		{"out of turn", order[1], true},
+		{"unknown player", "nobody", true},
+		{"first", order[0], false},
+		{"first again", order[0], true},
+		{"second", order[1], false},
+		{"third", order[2], false},
+	}
+	for _, tt := range tests {
+		if err := g.PassTurn(tt.playerID); (err != nil) != tt.wantErr {
+			t.Fatalf("%s: PassTurn() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if status := g.TurnStatus(); status.Round != 3 {
+		t.Errorf("round = %d after everyone passed, want 3", status.Round)
+	}
+}
+
+func TestTickTurnsEndsTurn(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(p *Player)
+		after   time.Duration
+		want    bool
+	}{
+		{"acting in time", func(p *Player) {}, time.Second, false},
+		{"timed out", func(p *Player) {}, DefaultTurnTimeout, true},
+		{"stunned", func(p *Player) {
+			p.Effects = []*StatusEffect{{Kind: EffectStun, Stacks: 1, ExpiresAt: time.Now().Add(time.Hour)}}
+		}, time.Second, true},
+		{"defeated", func(p *Player) { p.Health = 0 }, time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, Options{Mode: ModeTurnBased})
+			addTestPlayer(t, g, "a")
+			addTestPlayer(t, g, "b")
+			now := time.Now()
+
+			g.Mu.Lock()
+			defer g.Mu.Unlock()
+			g.turns.deadline = now.Add(g.TurnTimeout)
+			active := g.turns.activePlayerID()
+			tt.prepare(g.Players[active])
+
+			events := g.tickTurns(now.Add(tt.after))
+			if got := countEvents(events, EventTurnEnded) > 0; got != tt.want {
+				t.Errorf("turn ended = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBeginActionOutOfTurn(t *testing.T) {
+	g := newTestGame(t, Options{Mode: ModeTurnBased})
+	addTestPlayer(t, g, "a")
+	addTestPlayer(t, g, "b")
+
+	// a joined first and holds the turn
+	if err := g.Hide("b"); err == nil {
+		t.Error("a player acted out of turn")
+	}
+}
@@ -0,0 +1,166 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type Phase string
+
+const (
+	PhaseDawn  Phase = "dawn"
+	PhaseDay   Phase = "day"
+	PhaseDusk  Phase = "dusk"
+	PhaseNight Phase = "night"
+)
+
+type Weather string
+
+const (
+	WeatherClear Weather = "clear"
+	WeatherRain  Weather = "rain"
+	WeatherFog   Weather = "fog"
+	WeatherStorm Weather = "storm"
+)
+
+const (
+	PhaseDuration   = 2 * time.Minute
+	WeatherDuration = 3 * time.Minute
+
+	// Exits a player can make out at night without a Torch
+	nightVisibleExits = 1
+	// Dodge chance lost in fog
+	fogDodgePenalty = 10
+	// Travel time multiplier in a storm
+	stormTravelScale = 2.0
+)
+
+var phaseCycle = []Phase{PhaseDawn, PhaseDay, PhaseDusk, PhaseNight}
+
+// weatherOdds weights how likely each weather is to roll next.
+var weatherOdds = []struct {
+	weather Weather
+	weight  int
+}{
+	{WeatherClear, 5},
+	{WeatherRain, 2},
+	{WeatherFog, 2},
+	{WeatherStorm, 1},
+}
+
+type worldClock struct {
+	phase       int // index into phaseCycle
+	phaseEnds   time.Time
+	weather     Weather
+	weatherEnds time.Time
+}
+
+func newWorldClock(now time.Time) worldClock {
+	return worldClock{
+		phase:       1, // games start in daylight
+		phaseEnds:   now.Add(PhaseDuration),
+		weather:     WeatherClear,
+		weatherEnds: now.Add(WeatherDuration),
+	}
+}
+
+func (c *worldClock) Phase() Phase {
+	return phaseCycle[c.phase]
+}
+
+func rollWeather() Weather {
+	total := 0
+	for _, w := range weatherOdds {
+		total += w.weight
+	}
+	roll := rand.Intn(total)
+	for _, w := range weatherOdds {
+		if roll < w.weight {
+			return w.weather
+		}
+		roll -= w.weight
+	}
+	return WeatherClear
+}
+
+// WorldStatus describes the time of day and weather.
+type WorldStatus struct {
+	Phase         Phase     `json:"phase"`
+	PhaseEndsAt   time.Time `json:"phase_ends_at"`
+	Weather       Weather   `json:"weather"`
+	WeatherEndsAt time.Time `json:"weather_ends_at"`
+}
+
+func (g *Game) WorldStatus() WorldStatus {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	return WorldStatus{
+		Phase:         g.clock.Phase(),
+		PhaseEndsAt:   g.clock.phaseEnds,
+		Weather:       g.clock.weather,
+		WeatherEndsAt: g.clock.weatherEnds,
+	}
+}
+
+// tickClock advances the day/night cycle and weather. The caller must hold
+// g.Mu.
+func (g *Game) tickClock(now time.Time) []Event {
+	var events []Event
+
+	if !now.Before(g.clock.phaseEnds) {
+		g.clock.phase = (g.clock.phase + 1) % len(phaseCycle)
+		g.clock.phaseEnds = now.Add(PhaseDuration)
+		events = append(events, Event{
+			Type:    EventPhaseChanged,
+			Message: fmt.Sprintf("It is now %s", g.clock.Phase()),
+			Global:  true,
+		})
+	}
+
+	if !now.Before(g.clock.weatherEnds) {
+		g.clock.weatherEnds = now.Add(WeatherDuration)
+		if weather := rollWeather(); weather != g.clock.weather {
+			g.clock.weather = weather
+			events = append(events, Event{
+				Type:    EventWeatherChanged,
+				Message: fmt.Sprintf("The weather turns to %s", weather),
+				Global:  true,
+			})
+		}
+	}
+
+	return events
+}
+
+// VisibleConnections returns the exits player can see from where they stand.
+// At night only the nearest exit can be made out unless they carry a Torch.
+// The caller must hold g.Mu.
+func (g *Game) VisibleConnections(player *Player) []string {
+	connections := g.Locations[player.CurrentLocation].Connections
+	if g.clock.Phase() != PhaseNight || player.HasItemNamed("Torch") || len(connections) <= nightVisibleExits {
+		return connections
+	}
+	return connections[:nightVisibleExits]
+}
+
+// weatherDodgePenalty is the dodge chance lost to the current weather. The
+// caller must hold g.Mu.
+func (g *Game) weatherDodgePenalty() int {
+	if g.clock.weather == WeatherFog {
+		return fogDodgePenalty
+	}
+	return 0
+}
+
+// travelScale multiplies the cooldown of action under the current weather.
+// Storms slow travel only through cooldowns, so they do not slow simultaneous
+// games, where every queued move resolves at the next tick boundary. The
+// caller must hold g.Mu.
+func (g *Game) travelScale(action Action) float64 {
+	if g.clock.weather == WeatherStorm && (action == ActionMove || action == ActionFlee || action == ActionSneak) {
+		return stormTravelScale
+	}
+	return 1
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickClockPhases(t *testing.T) {
+	now := time.Now()
+	g := &Game{clock: newWorldClock(now)}
+
+	want := []Phase{PhaseDusk, PhaseNight, PhaseDawn, PhaseDay}
+	for i, phase := range want {
+		now = now.Add(PhaseDuration)
+		events := g.tickClock(now)
+		if g.clock.Phase() != phase {
+			t.Fatalf("step %d: phase = %s, want %s", i, g.clock.Phase(), phase)
+		}
+		if countEvents(events, EventPhaseChanged) != 1 {
+			t.Errorf("step %d: no phase_changed event", i)
+		}
+	}
+
+	if events := g.tickClock(now.Add(time.Second)); countEvents(events, EventPhaseChanged) != 0 {
+		t.Error("phase changed before its time")
+	}
+}
+
+func TestVisibleConnections(t *testing.T) {
+	tests := []struct {
+		name  string
+		phase Phase
+		torch bool
+		want  int
+	}{
+		{"day", PhaseDay, false, 3},
+		{"night", PhaseNight, false, nightVisibleExits},
+		{"night with a torch", PhaseNight, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{Locations: map[string]*Location{"here": {ID: "here", Connections: []string{"a", "b", "c"}}}}
+			for i, p := range phaseCycle {
+				if p == tt.phase {
+					g.clock.phase = i
+				}
+			}
+			player := &Player{CurrentLocation: "here"}
+			if tt.torch {
+				player.Inventory = []*Item{NewItem("Torch")}
+			}
+
+			if got := len(g.VisibleConnections(player)); got != tt.want {
+				t.Errorf("visible exits = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherModifiers(t *testing.T) {
+	tests := []struct {
+		weather   Weather
+		action    Action
+		wantDodge int
+		wantScale float64
+	}{
+		{WeatherClear, ActionMove, 0, 1},
+		{WeatherFog, ActionMove, fogDodgePenalty, 1},
+		{WeatherStorm, ActionMove, 0, stormTravelScale},
+		{WeatherStorm, ActionAttack, 0, 1},
+	}
+
+	for _, tt := range tests {
+		g := &Game{clock: worldClock{weather: tt.weather}}
+		if got := g.weatherDodgePenalty(); got != tt.wantDodge {
+			t.Errorf("%s: dodge penalty = %d, want %d", tt.weather, got, tt.wantDodge)
+		}
+		if got := g.travelScale(tt.action); got != tt.wantScale {
+			t.Errorf("%s %s: travel scale = %v, want %v", tt.weather, tt.action, got, tt.wantScale)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"game-api/accounts"
+	"game-api/game"
+)
+
+// maxAccountRequestBytes caps register and login bodies, which only hold a
+// username, a password and a display name.
+const maxAccountRequestBytes = 4 << 10
+
+// accountFromRequest returns the account whose token authorizes r, or nil
+// if r has no Authorization header or its token is not an account token,
+// such as a player token left over from another game. On failure it also
+// returns the status to respond with.
+func (s *Server) accountFromRequest(r *http.Request) (*accounts.Account, int, error) {
+	if r.Header.Get("Authorization") == "" {
+		return nil, 0, nil
+	}
+
+	claims, status, err := s.bearerClaims(r)
+	if err != nil {
+		return nil, status, err
+	}
+	if !claims.hasRole(RoleAccount) {
+		return nil, 0, nil
+	}
+
+	account, err := s.accounts.Get(claims.AccountID)
+	if err != nil {
+		return nil, http.StatusUnauthorized, fmt.Errorf("Account no longer exists")
+	}
+	return account, 0, nil
+}
+
+func (s *Server) requireAccount(next func(w http.ResponseWriter, r *http.Request, account *accounts.Account)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		account, status, err := s.accountFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if account == nil {
+			http.Error(w, "Not an account token", http.StatusForbidden)
+			return
+		}
+
+		next(w, r, account)
+	}
+}
+
+// writeAccount responds with the account and a fresh account token.
+func (s *Server) writeAccount(w http.ResponseWriter, account *accounts.Account, status int) {
+	token, err := s.generateAccountToken(account.ID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account":       account,
+		"token":         token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+	})
+}
+
+// handleRegister serves POST /accounts. Registrations are rate limited per
+// client address, since every one costs a password hash and a store write.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if ok, wait := s.signupLimiter.allow(clientAddr(r)); !ok {
+		writeRetryAfter(w, "Too many registrations", "", wait)
+		return
+	}
+
+	var req struct {
+		Username    string `json:"username"`
+		Password    string `json:"password"`
+		DisplayName string `json:"display_name"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxAccountRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accounts.New(req.Username, req.Password, req.DisplayName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.accounts.Create(account); err != nil {
+		if errors.Is(err, accounts.ErrUsernameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to create account", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeAccount(w, account, http.StatusCreated)
+}
+
+// handleLogin serves POST /accounts/login, exchanging a username and
+// password for an account token. Attempts are rate limited per client
+// address and per username, since every one costs a password hash.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if ok, wait := s.loginLimiter.allow("addr:" + clientAddr(r)); !ok {
+		writeRetryAfter(w, "Too many login attempts", "", wait)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxAccountRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if ok, wait := s.loginLimiter.allow("user:" + strings.ToLower(req.Username)); !ok {
+		writeRetryAfter(w, "Too many login attempts", "", wait)
+		return
+	}
+
+	account, err := accounts.Authenticate(s.accounts, req.Username, req.Password)
+	if err != nil {
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	s.writeAccount(w, account, http.StatusOK)
+}
+
+// clientAddr is the host part of r's remote address.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// accountGame is one game an account has a character in.
+type accountGame struct {
+	GameID     string     `json:"game_id"`
+	Name       string     `json:"name"`
+	State      game.State `json:"state"`
+	PlayerID   string     `json:"player_id"`
+	PlayerName string     `json:"player_name"`
+	Health     int        `json:"health"`
+}
+
+// accountGames finds the account's character in every game, newest game
+// first.
+func (s *Server) accountGames(accountID string) []accountGame {
+	s.gamesMu.RLock()
+	games := make([]*game.Game, 0, len(s.games))
+	for _, g := range s.games {
+		games = append(games, g)
+	}
+	s.gamesMu.RUnlock()
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].CreatedAt.After(games[j].CreatedAt)
+	})
+
+	found := make([]accountGame, 0)
+	for _, g := range games {
+		g.Mu.RLock()
+		for _, p := range g.Players {
+			if p.AccountID == accountID {
+				found = append(found, accountGame{
+					GameID:     g.ID,
+					Name:       g.Name,
+					State:      g.State(),
+					PlayerID:   p.ID,
+					PlayerName: p.Name,
+					Health:     p.Health,
+				})
+				break
+			}
+		}
+		g.Mu.RUnlock()
+	}
+	return found
+}
+
+// handleAccount serves /accounts/me: GET shows the account and its games,
+// PATCH changes its display name. Characters keep the name they joined
+// with.
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request, account *accounts.Account) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		var req struct {
+			DisplayName string `json:"display_name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		name := strings.TrimSpace(req.DisplayName)
+		if err := accounts.ValidateDisplayName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		account.DisplayName = name
+		if err := s.accounts.Update(account); err != nil {
+			http.Error(w, "Failed to update account", http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account": account,
+		"games":   s.accountGames(account.ID),
+	})
+}
+
+// reclaimPlayer hands an account's existing character back to it with a new
+// game token, e.g. after signing in on another device.
+func (s *Server) reclaimPlayer(w http.ResponseWriter, g *game.Game, player *game.Player) {
+	token, err := s.generateToken(g.ID, player.ID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"player":        player,
+		"token":         token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+		"reclaimed":     true,
+		"message":       "Welcome back.",
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// registerTestAccount registers an account and returns its token.
+func registerTestAccount(t *testing.T, s *Server, username string) string {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/accounts", "", map[string]string{"username": username, "password": "password1"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("registering %s: %d %s", username, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &resp)
+	return resp.Token
+}
+
+func TestRegisterErrors(t *testing.T) {
+	s := newTestServer(t)
+	registerTestAccount(t, s, "alice")
+
+	tests := []struct {
+		name string
+		body interface{}
+		want int
+	}{
+		{"bad body", "not an object", http.StatusBadRequest},
+		{"short password", map[string]string{"username": "bob", "password": "x"}, http.StatusBadRequest},
+		{"username taken", map[string]string{"username": "ALICE", "password": "password1"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, http.MethodPost, "/accounts", "", tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	registerTestAccount(t, s, "alice")
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     int
+	}{
+		{"right password", "alice", "password1", http.StatusOK},
+		{"wrong password", "alice", "password2", http.StatusUnauthorized},
+		{"unknown username", "bob", "password1", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, http.MethodPost, "/accounts/login", "", map[string]string{"username": tt.username, "password": tt.password})
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit func(s *Server)
+	}{
+		{"per address", func(s *Server) { s.loginLimiter.allow("addr:192.0.2.1") }},
+		{"per username", func(s *Server) { s.loginLimiter.allow("user:alice") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.loginLimiter = newRateLimiter(0.01, 1)
+			tt.limit(s)
+
+			w := do(t, s, http.MethodPost, "/accounts/login", "", map[string]string{"username": "Alice", "password": "password1"})
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want 429", w.Code)
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("no Retry-After header")
+			}
+		})
+	}
+}
+
+func TestRegisterLimits(t *testing.T) {
+	s := newTestServer(t)
+	s.signupLimiter = newRateLimiter(0.01, 1)
+
+	w := do(t, s, http.MethodPost, "/accounts", "", map[string]string{"username": "alice", "display_name": strings.Repeat("x", maxAccountRequestBytes)})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("oversized body: status = %d, want 400", w.Code)
+	}
+	w = do(t, s, http.MethodPost, "/accounts", "", map[string]string{"username": "alice", "password": "password1"})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("second registration: status = %d, want 429 with Retry-After", w.Code)
+	}
+}
+
+func TestAccountRoutes(t *testing.T) {
+	s := newTestServer(t)
+	accountToken := registerTestAccount(t, s, "alice")
+	g := createTestGame(t, s, nil)
+	_, playerToken := joinTestGame(t, s, g, "bob")
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   interface{}
+		want   int
+	}{
+		{"no token", http.MethodGet, "", nil, http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "garbage", nil, http.StatusUnauthorized},
+		{"player token", http.MethodGet, playerToken, nil, http.StatusForbidden},
+		{"account token", http.MethodGet, accountToken, nil, http.StatusOK},
+		{"empty display name", http.MethodPatch, accountToken, map[string]string{"display_name": " "}, http.StatusBadRequest},
+		{"rename", http.MethodPatch, accountToken, map[string]string{"display_name": "Al"}, http.StatusOK},
+		{"bad method", http.MethodDelete, accountToken, nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, tt.method, "/accounts/me", tt.token, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestJoinWithTokens(t *testing.T) {
+	s := newTestServer(t)
+	accountToken := registerTestAccount(t, s, "alice")
+	other := createTestGame(t, s, nil)
+	_, playerToken := joinTestGame(t, s, other, "bob")
+	g := createTestGame(t, s, nil)
+
+	join := func(token string) (int, string, bool) {
+		w := do(t, s, http.MethodPost, "/games/"+g.ID+"/players", token, map[string]string{"name": "carol"})
+		var resp struct {
+			Player struct {
+				ID        string `json:"id"`
+				AccountID string `json:"account_id"`
+			} `json:"player"`
+			Reclaimed bool `json:"reclaimed"`
+		}
+		if w.Code < 300 {
+			decode(t, w, &resp)
+		}
+		return w.Code, resp.Player.AccountID, resp.Reclaimed
+	}
+
+	// A player token from another game is ignored, not rejected
+	if code, accountID, _ := join(playerToken); code != http.StatusCreated || accountID != "" {
+		t.Errorf("join with player token = %d, account %q; want 201 with no account", code, accountID)
+	}
+	if code, _, _ := join("garbage"); code != http.StatusUnauthorized {
+		t.Errorf("join with bad token = %d, want 401", code)
+	}
+	code, accountID, _ := join(accountToken)
+	if code != http.StatusCreated || accountID == "" {
+		t.Fatalf("join with account token = %d, account %q; want 201 with an account", code, accountID)
+	}
+	if code, _, reclaimed := join(accountToken); code != http.StatusOK || !reclaimed {
+		t.Errorf("joining again = %d, reclaimed %v; want 200 and reclaimed", code, reclaimed)
+	}
+}
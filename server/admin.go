@@ -0,0 +1,264 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"game-api/game"
+	"game-api/utils"
+)
+
+// checkAdminCredential compares in constant time. Hashing first keeps the
+// comparison from leaking the credential's length.
+func (s *Server) checkAdminCredential(credential string) bool {
+	if s.config.AdminCredential == "" {
+		return false
+	}
+	want := sha256.Sum256([]byte(s.config.AdminCredential))
+	got := sha256.Sum256([]byte(credential))
+	return subtle.ConstantTimeCompare(want[:], got[:]) == 1
+}
+
+// handleAdminLogin serves POST /admin/login, exchanging the admin
+// credential from config for an admin token.
+func (s *Server) handleAdminLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.config.AdminCredential == "" {
+		http.Error(w, "Admin access is not configured", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Credential string `json:"credential"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !s.checkAdminCredential(req.Credential) {
+		http.Error(w, "Invalid admin credential", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := s.generateAdminToken(utils.SecureID(8))
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(token)
+}
+
+// handleAdminGames serves GET /admin/games, which lists every game,
+// including unlisted and private ones.
+func (s *Server) handleAdminGames(w http.ResponseWriter, r *http.Request, claims *Claims) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.gamesMu.RLock()
+	games := make([]*game.Game, 0, len(s.games))
+	for _, g := range s.games {
+		games = append(games, g)
+	}
+	s.gamesMu.RUnlock()
+
+	summaries := make([]GameSummary, 0, len(games))
+	for _, g := range games {
+		summaries = append(summaries, summarize(g))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"games": summaries,
+		"count": len(summaries),
+	})
+}
+
+// handleAdminGameRoutes serves /admin/games/{id}: GET inspects the game,
+// DELETE removes it, and POST .../kick and .../bots moderate it.
+func (s *Server) handleAdminGameRoutes(w http.ResponseWriter, r *http.Request, claims *Claims) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/games/"), "/")
+
+	g := s.getGame(parts[0])
+	if g == nil {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.handleInspectGame(w, r, g, claims)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.handleDeleteGame(w, r, g, claims)
+	case len(parts) == 2 && parts[1] == "kick":
+		s.handleAdminKick(w, r, g, claims)
+	case len(parts) == 2 && parts[1] == "bots":
+		s.handleAddBot(w, r, g, claims)
+	case len(parts) == 1:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleInspectGame shows everything about a game, including what players
+// cannot see: hidden players, invites and the audit log.
+func (s *Server) handleInspectGame(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	invites, err := g.Invites(game.AdminActorID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	audit := g.AuditLog("", 0)
+	turn := g.TurnStatus()
+
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	response := map[string]interface{}{
+		"game_id":    g.ID,
+		"options":    optionsOf(g),
+		"state":      g.State(),
+		"created_at": g.CreatedAt,
+		"owner_id":   g.OwnerID,
+		"spectators": g.SpectatorCount(),
+		"locations":  g.Locations,
+		"players":    g.Players,
+		"invites":    invites,
+		"audit":      audit,
+	}
+	if turn != nil {
+		response["turn"] = turn
+	}
+	if len(g.Teams) > 0 {
+		response["team_scores"] = g.TeamScores
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleDeleteGame removes a game and closes every stream watching it. The
+// game's owner can delete it too, through DELETE /games/{id}.
+func (s *Server) handleDeleteGame(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	s.removeGame(g.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Game deleted",
+	})
+}
+
+// handleAdminKick removes a player as an admin. It works like the owner's
+// kick action but needs no character in the game.
+func (s *Server) handleAdminKick(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Target          string `json:"target"`
+		DurationSeconds int    `json:"duration_seconds"`
+		Reason          string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if err := g.Kick(actorOf(claims), req.Target, duration, req.Reason); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.tokens.revokeSubject(playerSubject(g.ID, req.Target))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Player kicked",
+	})
+}
+
+// handleAddBot adds a bot player and returns its token. Bots act like
+// players but never become the game's owner.
+func (s *Server) handleAddBot(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The body is optional; bots are named for their ID by default
+	var req struct {
+		Name       string `json:"name"`
+		Team       string `json:"team"`
+		Password   string `json:"password"`
+		InviteCode string `json:"invite_code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	code, err := s.joinCode(r, g, req.Password, req.InviteCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	player := newPlayer(g, req.Name)
+	if player == nil {
+		http.Error(w, "No locations available", http.StatusInternalServerError)
+		return
+	}
+	if player.Name == "" {
+		player.Name = "Bot " + player.ID
+	}
+	player.Bot = true
+
+	if err := g.AddPlayer(player, req.Team, code); err != nil {
+		status := http.StatusBadRequest
+		var joinErr *game.JoinError
+		if errors.Is(err, game.ErrGameFull) {
+			status = http.StatusConflict
+		} else if errors.As(err, &joinErr) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	token, err := s.generateBotToken(g.ID, player.ID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"player":        player,
+		"token":         token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+	})
+}
@@ -2,80 +2,126 @@ package server
 
 import (
 	"fmt"
-	"net/http"
-	"strings"
 	"time"
 
+	"game-api/game"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	PlayerID string `json:"player_id"`
-	GameID   string `json:"game_id"`
+	PlayerID  string `json:"player_id,omitempty"`
+	GameID    string `json:"game_id,omitempty"`
+	AccountID string `json:"account_id,omitempty"`
+	Roles     []Role `json:"roles"`
+	SessionID string `json:"sid,omitempty"` // the login this token was refreshed from
+	jwt.RegisteredClaims
+}
+
+func (s *Server) generateToken(gameID, playerID string) (tokenPair, error) {
+	return s.issueTokens(Claims{
+		PlayerID:         playerID,
+		GameID:           gameID,
+		Roles:            []Role{RolePlayer},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: playerID},
+	})
+}
+
+// generateBotToken issues a player token marked as a bot's.
+func (s *Server) generateBotToken(gameID, playerID string) (tokenPair, error) {
+	return s.issueTokens(Claims{
+		PlayerID:         playerID,
+		GameID:           gameID,
+		Roles:            []Role{RolePlayer, RoleBot},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: playerID},
+	})
+}
+
+// generateAccountToken issues a token for an account rather than a game.
+// It has no GameID, so no game accepts it in place of a player token; it is
+// exchanged for one by joining.
+func (s *Server) generateAccountToken(accountID string) (tokenPair, error) {
+	return s.issueTokens(Claims{
+		AccountID:        accountID,
+		Roles:            []Role{RoleAccount},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: accountID},
+	})
+}
+
+// generateSpectatorToken issues a token that can stream a game's events but
+// not act in it. Subject is the spectator ID.
+func (s *Server) generateSpectatorToken(gameID, spectatorID string) (tokenPair, error) {
+	return s.issueTokens(Claims{
+		GameID:           gameID,
+		Roles:            []Role{RoleSpectator},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: spectatorID},
+	})
+}
+
+// generateAdminToken issues a server-wide admin token. It is only handed
+// out in exchange for the admin credential from config.
+func (s *Server) generateAdminToken(adminID string) (tokenPair, error) {
+	return s.issueTokens(Claims{
+		Roles:            []Role{RoleAdmin},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: adminID},
+	})
+}
+
+// InviteClaims back a signed invite link. The link carries an invite code
+// for one game; the invite itself still decides whether it can be used.
+type InviteClaims struct {
+	GameID string `json:"game_id"`
+	Code   string `json:"code"`
 	jwt.RegisteredClaims
 }
 
-func (s *Server) generateToken(gameID, playerID string) (string, error) {
-	claims := Claims{
-		PlayerID: playerID,
-		GameID:   gameID,
+// generateInviteToken signs a link for invite. The link expires with the
+// invite, or after InviteLinkTTL if that comes first.
+func (s *Server) generateInviteToken(gameID string, invite game.Invite) (string, error) {
+	now := time.Now()
+	expiresAt := now.Add(InviteLinkTTL)
+	if !invite.ExpiresAt.IsZero() && invite.ExpiresAt.Before(expiresAt) {
+		expiresAt = invite.ExpiresAt
+	}
+	claims := InviteClaims{
+		GameID: gameID,
+		Code:   invite.Code,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   playerID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.config.JWTSecret)
+	return s.keys.sign(claims)
 }
 
-func (s *Server) validateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.config.JWTSecret, nil
-	})
+func (s *Server) validateInviteToken(tokenString string) (*InviteClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &InviteClaims{}, s.keys.keyFunc)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*InviteClaims); ok && token.Valid && claims.Code != "" {
 		return claims, nil
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	return nil, fmt.Errorf("invalid invite link")
 }
 
-func (s *Server) requireAuth(gameID string, next func(w http.ResponseWriter, r *http.Request, claims *Claims)) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		tokenString := parts[1]
+func (s *Server) validateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.keyFunc)
 
-		claims, err := s.validateToken(tokenString)
-		if err != nil {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		if claims.GameID != gameID {
-			http.Error(w, "Token not valid for this game", http.StatusForbidden)
-			return
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if s.tokens.isRevoked(claims.ID) {
+			return nil, fmt.Errorf("token has been revoked")
 		}
-
-		next(w, r, claims)
+		return claims, nil
 	}
+
+	return nil, fmt.Errorf("invalid token")
 }
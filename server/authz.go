@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"game-api/game"
+)
+
+// Role is what a token lets its holder do. Roles are carried in the token,
+// except RoleOwner, which follows the game's current owner and is worked out
+// per request.
+type Role string
+
+const (
+	RolePlayer    Role = "player"
+	RoleBot       Role = "bot" // a player driven by a server-issued bot token
+	RoleSpectator Role = "spectator"
+	RoleOwner     Role = "owner"
+	RoleAdmin     Role = "admin"   // server-wide; valid for every game
+	RoleAccount   Role = "account" // an account, not yet in any game
+)
+
+// Role sets routes require. A caller needs any one of the roles listed.
+var (
+	playerRoles    = []Role{RolePlayer}
+	watcherRoles   = []Role{RolePlayer, RoleSpectator, RoleAdmin}
+	moderatorRoles = []Role{RoleOwner, RoleAdmin}
+	adminRoles     = []Role{RoleAdmin}
+	accountRoles   = []Role{RoleAccount}
+)
+
+func (c *Claims) hasRole(role Role) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// gameRoles is every role the claims hold in g, including ownership.
+func gameRoles(g *game.Game, claims *Claims) []Role {
+	roles := claims.Roles
+	g.Mu.RLock()
+	if claims.PlayerID != "" && claims.PlayerID == g.OwnerID {
+		roles = append(append([]Role(nil), roles...), RoleOwner)
+	}
+	g.Mu.RUnlock()
+	return roles
+}
+
+func anyRole(have, want []Role) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roleError explains which roles a route needs.
+func roleError(want []Role) string {
+	names := make([]string, len(want))
+	for i, r := range want {
+		names[i] = string(r)
+	}
+	return "Requires role: " + strings.Join(names, " or ")
+}
+
+// actorOf is who the claims act as in game methods. Admins act under
+// game.AdminActorID, so their moderation shows up as such in audit logs.
+func actorOf(claims *Claims) string {
+	if claims.hasRole(RoleAdmin) {
+		return game.AdminActorID
+	}
+	return claims.PlayerID
+}
+
+// bearerClaims validates the request's bearer token. On failure it also
+// returns the status to respond with. This is the only place the
+// Authorization header is parsed.
+func (s *Server) bearerClaims(r *http.Request) (*Claims, int, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, http.StatusUnauthorized, fmt.Errorf("Missing authorization header")
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, http.StatusUnauthorized, fmt.Errorf("Invalid authorization header format")
+	}
+
+	claims, err := s.validateToken(parts[1])
+	if err != nil {
+		return nil, http.StatusUnauthorized, fmt.Errorf("Invalid or expired token")
+	}
+	return claims, 0, nil
+}
+
+// requireRole wraps a server-level route that needs one of roles.
+func (s *Server) requireRole(roles []Role, next func(w http.ResponseWriter, r *http.Request, claims *Claims)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, status, err := s.bearerClaims(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if !anyRole(claims.Roles, roles) {
+			http.Error(w, roleError(roles), http.StatusForbidden)
+			return
+		}
+
+		next(w, r, claims)
+	}
+}
+
+type gameHandler func(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims)
+
+// requireGameRole wraps a route under /games/{id} that needs one of roles in
+// g. Tokens must be issued for g, except admin tokens, which are valid for
+// every game.
+func (s *Server) requireGameRole(g *game.Game, roles []Role, next gameHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, status, err := s.bearerClaims(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		if claims.GameID != g.ID && !claims.hasRole(RoleAdmin) {
+			http.Error(w, "Token not valid for this game", http.StatusForbidden)
+			return
+		}
+		if !anyRole(gameRoles(g, claims), roles) {
+			http.Error(w, roleError(roles), http.StatusForbidden)
+			return
+		}
+		s.idle.touch(g.ID, time.Now())
+
+		next(w, r, g, claims)
+	}
+}
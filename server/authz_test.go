@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+// adminToken logs in with the test server's admin credential.
+func adminToken(t *testing.T, s *Server) string {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/admin/login", "", map[string]string{"credential": "test-admin"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin login: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &resp)
+	return resp.Token
+}
+
+func TestRequireGameRole(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, nil)
+	_, ownerToken := joinTestGame(t, s, g, "owner")
+	_, playerToken := joinTestGame(t, s, g, "player")
+	_, otherToken := joinTestGame(t, s, createTestGame(t, s, nil), "stranger")
+	spectator, _ := s.generateSpectatorToken(g.ID, "s")
+	account, _ := s.generateAccountToken("a")
+	admin := adminToken(t, s)
+
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		token  string
+		want   int
+	}{
+		{"player reads context", http.MethodGet, "/players/me", playerToken, http.StatusOK},
+		{"spectator reads player context", http.MethodGet, "/players/me", spectator.AccessToken, http.StatusForbidden},
+		{"token for another game", http.MethodGet, "/players/me", otherToken, http.StatusForbidden},
+		{"account token", http.MethodGet, "/players/me", account.AccessToken, http.StatusForbidden},
+		{"no token", http.MethodGet, "/players/me", "", http.StatusUnauthorized},
+		{"malformed header", http.MethodGet, "/players/me", "a b", http.StatusUnauthorized},
+		{"player reads audit log", http.MethodGet, "/audit", playerToken, http.StatusForbidden},
+		{"owner reads audit log", http.MethodGet, "/audit", ownerToken, http.StatusOK},
+		{"admin reads audit log", http.MethodGet, "/audit", admin, http.StatusOK},
+		{"player deletes game", http.MethodDelete, "", playerToken, http.StatusForbidden},
+		{"owner deletes game", http.MethodDelete, "", ownerToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, tt.method, "/games/"+g.ID+tt.route, tt.token, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminLogin(t *testing.T) {
+	s := newTestServer(t)
+	if w := do(t, s, http.MethodPost, "/admin/login", "", map[string]string{"credential": "guess"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong credential: status = %d, want 401", w.Code)
+	}
+
+	// Every login is its own subject, so revoking one leaves the others
+	a, err := s.validateToken(adminToken(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.validateToken(adminToken(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Subject == b.Subject {
+		t.Errorf("two admin logins share subject %q", a.Subject)
+	}
+
+	s.config.AdminCredential = ""
+	if w := do(t, s, http.MethodPost, "/admin/login", "", map[string]string{"credential": ""}); w.Code != http.StatusNotFound {
+		t.Errorf("admin access off: status = %d, want 404", w.Code)
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, map[string]interface{}{"visibility": "private"})
+	_, playerToken := joinTestGame(t, s, createTestGame(t, s, nil), "player")
+	admin := adminToken(t, s)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"list as player", http.MethodGet, "/admin/games", playerToken, http.StatusForbidden},
+		{"list", http.MethodGet, "/admin/games", admin, http.StatusOK},
+		{"inspect private game", http.MethodGet, "/admin/games/" + g.ID, admin, http.StatusOK},
+		{"add bot to private game without invite", http.MethodPost, "/admin/games/" + g.ID + "/bots", admin, http.StatusForbidden},
+		{"unknown route", http.MethodGet, "/admin/games/" + g.ID + "/nope", admin, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/admin/games/" + g.ID, admin, http.StatusOK},
+		{"delete again", http.MethodDelete, "/admin/games/" + g.ID, admin, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, tt.method, tt.path, tt.token, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminBots(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, nil)
+
+	w := do(t, s, http.MethodPost, "/admin/games/"+g.ID+"/bots", adminToken(t, s), nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &resp)
+
+	claims, err := s.validateToken(resp.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.hasRole(RoleBot) || !claims.hasRole(RolePlayer) {
+		t.Errorf("bot token roles = %v", claims.Roles)
+	}
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	if g.OwnerID != "" {
+		t.Errorf("bot became the owner")
+	}
+}
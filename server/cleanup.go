@@ -0,0 +1,105 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// IdleGameTimeout is how long a game registered for cleanup may go
+	// without requests or connected clients before it is removed.
+	IdleGameTimeout = 10 * time.Minute
+
+	cleanupInterval = time.Minute
+)
+
+// idleTracker remembers when each game registered for cleanup was last
+// used. A background sweeper runs only while games are registered.
+type idleTracker struct {
+	mu       sync.Mutex
+	lastUsed map[string]time.Time
+	running  bool
+}
+
+func newIdleTracker() *idleTracker {
+	return &idleTracker{lastUsed: make(map[string]time.Time)}
+}
+
+// touch records a request to a game. Games that are not registered are
+// ignored.
+func (it *idleTracker) touch(gameID string, now time.Time) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if _, ok := it.lastUsed[gameID]; ok {
+		it.lastUsed[gameID] = now
+	}
+}
+
+func (it *idleTracker) forget(gameID string) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	delete(it.lastUsed, gameID)
+}
+
+// registerIdleCleanup has the game removed once it goes unused for
+// IdleGameTimeout, and starts the sweeper if it is idle.
+func (s *Server) registerIdleCleanup(gameID string) {
+	it := s.idle
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	it.lastUsed[gameID] = time.Now()
+	if !it.running {
+		it.running = true
+		go s.runCleanup()
+	}
+}
+
+// runCleanup sweeps every cleanupInterval until no games are registered.
+func (s *Server) runCleanup() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !s.sweepIdleGames(time.Now()) {
+			return
+		}
+	}
+}
+
+// sweepIdleGames removes registered games that have been unused for
+// IdleGameTimeout. A game with clients streaming its events counts as in
+// use. It reports whether the sweeper should keep running.
+func (s *Server) sweepIdleGames(now time.Time) bool {
+	it := s.idle
+	it.mu.Lock()
+	var stale []string
+	for id, last := range it.lastUsed {
+		if now.Sub(last) < IdleGameTimeout {
+			continue
+		}
+		g := s.getGame(id)
+		switch {
+		case g == nil:
+			delete(it.lastUsed, id)
+		case g.ClientCount() > 0:
+			it.lastUsed[id] = now
+		default:
+			stale = append(stale, id)
+		}
+	}
+	it.mu.Unlock()
+
+	// removeGame forgets each game, so it runs without it.mu
+	for _, id := range stale {
+		s.removeGame(id)
+	}
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if len(it.lastUsed) == 0 {
+		it.running = false
+		return false
+	}
+	return true
+}
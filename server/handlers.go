@@ -2,8 +2,12 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,51 +27,49 @@ func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; an empty one creates a public real-time game
+	var cfg GameConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	opts, errs := cfg.options()
+	if errs != nil {
+		writeFieldErrors(w, errs)
+		return
+	}
+
 	gameID := utils.GenerateID(8)
-	g := game.NewGame(gameID)
+	opts.ChatFilter = s.chatFilter
+	opts.Quests = s.quests
+	opts.OnUpdate = func() { s.lobby.updated(gameID) }
+
+	g := game.NewGame(gameID, opts)
 	s.addGame(g)
 
 	response := map[string]interface{}{
 		"game_id":   g.ID,
+		"name":      g.Name,
+		"mode":      g.Mode,
+		"seed":      g.Seed,
+		"options":   optionsOf(g),
 		"locations": g.Locations,
 		"message":   "Game created successfully",
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
-}
-
-func (s *Server) listGames(w http.ResponseWriter, r *http.Request) {
-	s.gamesMu.RLock()
-	defer s.gamesMu.RUnlock()
-
-	type GameSummary struct {
-		ID            string `json:"id"`
-		PlayerCount   int    `json:"player_count"`
-		LocationCount int    `json:"location_count"`
-	}
-
-	games := make([]GameSummary, 0, len(s.games))
-
-	for _, g := range s.games {
-		g.Mu.RLock()
-		summary := GameSummary{
-			ID:            g.ID,
-			PlayerCount:   len(g.Players),
-			LocationCount: len(g.Locations),
+	// The creator needs the first invite to get into the game themselves
+	if opts.InviteCode != "" {
+		view, err := s.viewInvite(g, game.Invite{Code: opts.InviteCode})
+		if err != nil {
+			http.Error(w, "Failed to sign invite link", http.StatusInternalServerError)
+			return
 		}
-		g.Mu.RUnlock()
-
-		games = append(games, summary)
-	}
-
-	response := map[string]interface{}{
-		"games": games,
-		"count": len(games),
+		response["invite_code"] = view.Code
+		response["invite_link"] = view.Link
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -81,62 +83,75 @@ func (s *Server) handleGameRoutes(w http.ResponseWriter, r *http.Request) {
 	}
 
 	gameID := parts[0]
+
+	// Game IDs are 8 characters, so "events" cannot collide with one
+	if gameID == "events" && len(parts) == 1 {
+		s.handleLobbySSE(w, r)
+		return
+	}
+
 	g := s.getGame(gameID)
 	if g == nil {
 		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 
+	// Each route names the roles it needs; viewing, joining and spectating
+	// need none
 	if len(parts) == 1 {
-		s.handleGetGame(w, r, g)
+		if r.Method == http.MethodDelete {
+			s.requireGameRole(g, moderatorRoles, s.handleDeleteGame)(w, r)
+		} else {
+			s.handleGetGame(w, r, g)
+		}
 	} else {
 		switch parts[1] {
 		case "players":
 			if len(parts) == 3 && parts[2] == "me" {
-				s.handleGetPlayerContext(w, r, g) // ← New endpoint
+				s.requireGameRole(g, playerRoles, s.handleGetPlayerContext)(w, r)
 			} else {
 				s.handlePlayers(w, r, g)
 			}
 		case "events":
-			s.handleSSE(w, r, g)
+			s.requireGameRole(g, watcherRoles, s.handleSSE)(w, r)
 		case "actions":
-			s.handleActions(w, r, g)
+			s.requireGameRole(g, playerRoles, s.handleActions)(w, r)
+		case "chat":
+			s.requireGameRole(g, playerRoles, s.handleChatHistory)(w, r)
+		case "audit":
+			s.requireGameRole(g, moderatorRoles, s.handleAuditLog)(w, r)
+		case "quests":
+			s.requireGameRole(g, playerRoles, s.handleQuests)(w, r)
+		case "spectators":
+			s.handleSpectators(w, r, g)
+		case "invites":
+			if len(parts) == 3 {
+				code := parts[2]
+				s.requireGameRole(g, moderatorRoles, func(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+					s.handleRevokeInvite(w, r, g, claims, code)
+				})(w, r)
+			} else {
+				s.requireGameRole(g, moderatorRoles, s.handleInvites)(w, r)
+			}
 		default:
 			http.Error(w, "Not found", http.StatusNotFound)
 		}
 	}
 }
 
-func (s *Server) handleGetPlayerContext(w http.ResponseWriter, r *http.Request, g *game.Game) {
+func (s *Server) handleGetPlayerContext(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-		return
-	}
-
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-		return
-	}
-
-	claims, err := s.validateToken(parts[1])
-	if err != nil {
-		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-		return
-	}
-
-	if claims.GameID != g.ID {
-		http.Error(w, "Token not valid for this game", http.StatusForbidden)
-		return
-	}
-
 	playerID := claims.PlayerID
+	turn := g.TurnStatus()
+	tick := g.TickStatus(playerID)
+	party := g.GetParty(playerID)
+	trade := g.GetTrade(playerID)
+	_, quests := g.QuestLog(playerID)
+	world := g.WorldStatus()
 
 	g.Mu.RLock()
 	defer g.Mu.RUnlock()
@@ -153,44 +168,126 @@ func (s *Server) handleGetPlayerContext(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	connectedLocations := make([]*game.Location, 0, len(currentLocation.Connections))
-	for _, connID := range currentLocation.Connections {
+	// Hidden players are left out of the counts, or occupancy would give
+	// them away
+	occupancy := make(map[string]int)
+	for _, p := range g.Players {
+		if p.Health > 0 && game.CanSeePlayer(playerID, p) {
+			occupancy[p.CurrentLocation]++
+		}
+	}
+
+	type LocationSummary struct {
+		*game.Location
+		Occupancy int `json:"occupancy"`
+	}
+
+	// Only list the exits the player can currently see
+	visible := g.VisibleConnections(player)
+	here := *currentLocation
+	here.Connections = visible
+
+	connectedLocations := make([]LocationSummary, 0, len(visible))
+	for _, connID := range visible {
 		if loc := g.Locations[connID]; loc != nil {
-			connectedLocations = append(connectedLocations, loc)
+			connectedLocations = append(connectedLocations, LocationSummary{Location: loc, Occupancy: occupancy[connID]})
 		}
 	}
 
 	playersHere := make([]*game.Player, 0)
 	for _, p := range g.Players {
-		if p.CurrentLocation == player.CurrentLocation && p.ID != playerID {
+		if p.CurrentLocation == player.CurrentLocation && p.ID != playerID && game.CanSeePlayer(playerID, p) {
 			playersHere = append(playersHere, p)
 		}
 	}
 
 	response := map[string]interface{}{
 		"player":              player,
-		"current_location":    currentLocation,
+		"current_location":    LocationSummary{Location: &here, Occupancy: occupancy[currentLocation.ID]},
 		"connected_locations": connectedLocations,
 		"players_here":        playersHere,
+		"cooldowns":           player.CooldownsRemaining(time.Now()),
+		"engaged_with":        g.EngagedWith(player, time.Now()),
+		"quests":              quests,
+		"world":               world,
+	}
+	if turn != nil {
+		response["turn"] = turn
+	}
+	if tick != nil {
+		response["tick"] = tick
+	}
+	if party != nil {
+		response["party"] = party
+	}
+	if trade != nil {
+		response["trade"] = trade
+	}
+	if len(g.Teams) > 0 {
+		response["team_scores"] = g.TeamScores
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// publicPlayer is what anyone can see of a player in GET /games/{id}.
+// Locations, stealth, effects and inventories are left to the player's own
+// context.
+type publicPlayer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Health int    `json:"health"`
+	Team   string `json:"team,omitempty"`
+	Bot    bool   `json:"bot,omitempty"`
+}
+
+// publicPlayers lists the game's players for the public view. The caller
+// must hold g.Mu.
+func publicPlayers(g *game.Game) map[string]publicPlayer {
+	players := make(map[string]publicPlayer, len(g.Players))
+	for id, p := range g.Players {
+		players[id] = publicPlayer{ID: p.ID, Name: p.Name, Health: p.Health, Team: p.Team, Bot: p.Bot}
+	}
+	return players
+}
+
 func (s *Server) handleGetGame(w http.ResponseWriter, r *http.Request, g *game.Game) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	turn := g.TurnStatus()
+
 	g.Mu.RLock()
 	defer g.Mu.RUnlock()
 
 	response := map[string]interface{}{
-		"game_id":   g.ID,
-		"locations": g.Locations,
-		"players":   g.Players,
+		"game_id":       g.ID,
+		"options":       optionsOf(g),
+		"name":          g.Name,
+		"tags":          g.Tags,
+		"created_at":    g.CreatedAt,
+		"max_players":   g.MaxPlayers,
+		"owner_id":      g.OwnerID,
+		"spectators":    g.SpectatorCount(),
+		"mode":          g.Mode,
+		"seed":          g.Seed,
+		"friendly_fire": g.FriendlyFire,
+		"locations":     g.Locations,
+		"players":       publicPlayers(g),
+	}
+	if len(g.Teams) > 0 {
+		response["teams"] = g.Teams
+		response["team_scores"] = g.TeamScores
+		response["score_limit"] = g.ScoreLimit
+		if g.Winner != "" {
+			response["winner"] = g.Winner
+		}
+	}
+	if turn != nil {
+		response["turn"] = turn
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -204,7 +301,10 @@ func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request, g *game.G
 	}
 
 	var req struct {
-		Name string `json:"name"`
+		Name       string `json:"name"`
+		Team       string `json:"team"`
+		Password   string `json:"password"`
+		InviteCode string `json:"invite_code"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -212,28 +312,62 @@ func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request, g *game.G
 		return
 	}
 
+	// Joining with an account token ties the character to the account, and
+	// joining again hands back the same character
+	account, status, err := s.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if account != nil {
+		if existing := g.PlayerByAccount(account.ID); existing != nil {
+			s.reclaimPlayer(w, g, existing)
+			return
+		}
+		if req.Name == "" {
+			req.Name = account.DisplayName
+		}
+	}
+
 	if req.Name == "" {
 		http.Error(w, "Name is required", http.StatusBadRequest)
 		return
 	}
 
-	playerID := utils.GenerateID(6)
-	startLocation := g.GetRandomLocation()
-	if startLocation == nil {
-		http.Error(w, "No locations available", http.StatusInternalServerError)
+	code, err := s.joinCode(r, g, req.Password, req.InviteCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 
-	player := &game.Player{
-		ID:              playerID,
-		Name:            req.Name,
-		CurrentLocation: startLocation.ID,
-		Health:          100,
-		Strength:        game.RollAttribute(),
-		Dexterity:       game.RollAttribute(),
+	player := newPlayer(g, req.Name)
+	if player == nil {
+		http.Error(w, "No locations available", http.StatusInternalServerError)
+		return
+	}
+	playerID := player.ID
+	if account != nil {
+		player.AccountID = account.ID
 	}
 
-	g.AddPlayer(player)
+	if err := g.AddPlayer(player, req.Team, code); err != nil {
+		// Another request for the same account joined first
+		if errors.Is(err, game.ErrAccountJoined) {
+			if existing := g.PlayerByAccount(account.ID); existing != nil {
+				s.reclaimPlayer(w, g, existing)
+				return
+			}
+		}
+		status := http.StatusBadRequest
+		var joinErr *game.JoinError
+		if errors.Is(err, game.ErrGameFull) {
+			status = http.StatusConflict
+		} else if errors.As(err, &joinErr) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
 
 	token, err := s.generateToken(g.ID, playerID)
 	if err != nil {
@@ -242,9 +376,11 @@ func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request, g *game.G
 	}
 
 	response := map[string]interface{}{
-		"player":  player,
-		"token":   token,
-		"message": "Player created successfully.",
+		"player":        player,
+		"token":         token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+		"message":       "Player created successfully.",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -252,40 +388,99 @@ func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request, g *game.G
 	json.NewEncoder(w).Encode(response)
 }
 
-func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, g *game.Game) {
+// newPlayer rolls a fresh character for g at a random location. It returns
+// nil if the game has no locations.
+func newPlayer(g *game.Game, name string) *game.Player {
+	startLocation := g.GetRandomLocation()
+	if startLocation == nil {
+		return nil
+	}
+
+	return &game.Player{
+		ID:              utils.GenerateID(6),
+		Name:            name,
+		CurrentLocation: startLocation.ID,
+		Health:          g.Ruleset.StartingHealth,
+		Strength:        game.RollAttribute(),
+		Dexterity:       game.RollAttribute(),
+		Inventory:       game.StarterItems(),
+	}
+}
+
+// handleSpectators issues a spectator token. Spectators stream every public
+// event in the game but never join it.
+func (s *Server) handleSpectators(w http.ResponseWriter, r *http.Request, g *game.Game) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-		return
+	// Private games can only be watched by someone holding an invite
+	if g.Visibility == game.VisibilityPrivate {
+		var req struct {
+			InviteCode string `json:"invite_code"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		code, err := s.joinCode(r, g, "", req.InviteCode)
+		if err == nil {
+			err = g.CheckInvite(code)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 	}
 
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+	spectatorID := utils.GenerateID(6)
+	token, err := s.generateSpectatorToken(g.ID, spectatorID)
+	if err != nil {
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	claims, err := s.validateToken(parts[1])
-	if err != nil {
-		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-		return
+	response := map[string]interface{}{
+		"spectator_id":  spectatorID,
+		"token":         token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+		"message":       "Spectator token created. Stream events from /games/" + g.ID + "/events.",
 	}
 
-	if claims.GameID != g.ID {
-		http.Error(w, "Token not valid for this game", http.StatusForbidden)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// queuedActions are resolved at the tick boundary in simultaneous games.
+var queuedActions = map[string]bool{
+	"move":   true,
+	"attack": true,
+	"flee":   true,
+	"hide":   true,
+	"sneak":  true,
+	"search": true,
+	"use":    true,
+}
+
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	playerID := claims.PlayerID
 
 	var req struct {
-		Action string `json:"action"`
-		Target string `json:"target"`
+		Action          string   `json:"action"`
+		Target          string   `json:"target"`
+		Message         string   `json:"message"`
+		ItemIDs         []string `json:"item_ids"`
+		DurationSeconds int      `json:"duration_seconds"`
+		EventIDs        []string `json:"event_ids"`
+		Reason          string   `json:"reason"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -299,10 +494,33 @@ func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, g *game.G
 		return
 	}
 
+	if ok, wait := s.actionLimiter.allow(g.ID + "/" + playerID); !ok {
+		writeRetryAfter(w, "Too many requests", "", wait)
+		return
+	}
+
+	// Simultaneous games resolve combat, movement and stealth at the tick boundary
+	if g.Mode == game.ModeSimultaneous && queuedActions[req.Action] {
+		resolveAt, err := g.QueueAction(playerID, game.Action(req.Action), req.Target)
+		if err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "queued",
+			"message":    req.Action + " queued for the next tick",
+			"resolve_at": resolveAt,
+		})
+		return
+	}
+
 	switch req.Action {
 	case "move":
 		if err := g.MovePlayer(playerID, req.Target); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeActionError(w, err)
 			return
 		}
 
@@ -314,7 +532,7 @@ func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, g *game.G
 
 	case "attack":
 		if err := g.AttackPlayer(playerID, req.Target); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeActionError(w, err)
 			return
 		}
 
@@ -324,69 +542,366 @@ func (s *Server) handleActions(w http.ResponseWriter, r *http.Request, g *game.G
 			"message": "Attack executed",
 		})
 
+	case "flee":
+		if err := g.Flee(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Flee attempted",
+		})
+
+	case "hide":
+		if err := g.Hide(playerID); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Hidden",
+		})
+
+	case "sneak":
+		if err := g.Sneak(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Player sneaked to " + req.Target,
+		})
+
+	case "search":
+		if err := g.Search(playerID); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Search complete",
+		})
+
+	case "use":
+		if err := g.UseItem(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Item used",
+		})
+
+	case "say", "whisper", "shout", "global", "party":
+		if err := g.Chat(playerID, game.ChatChannel(req.Action), req.Target, req.Message); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Message sent",
+		})
+
+	case "invite":
+		if err := g.InviteToParty(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Invitation sent",
+		})
+
+	case "accept":
+		if err := g.AcceptPartyInvite(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Joined party",
+		})
+
+	case "leave":
+		if err := g.LeaveParty(playerID); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Left party",
+		})
+
+	case "trade_open":
+		tradeID, err := g.OpenTrade(playerID, req.Target)
+		if err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":   "success",
+			"message":  "Trade opened",
+			"trade_id": tradeID,
+		})
+
+	case "trade_offer":
+		if err := g.OfferItems(playerID, req.Target, req.ItemIDs); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Offer updated",
+		})
+
+	case "trade_confirm":
+		if err := g.ConfirmTrade(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Trade confirmed",
+		})
+
+	case "trade_cancel":
+		if err := g.CancelTrade(playerID, req.Target); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Trade cancelled",
+		})
+
+	case "mute":
+		duration := time.Duration(req.DurationSeconds) * time.Second
+		if err := g.Mute(playerID, req.Target, duration, req.Reason); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Player muted",
+		})
+
+	case "kick":
+		duration := time.Duration(req.DurationSeconds) * time.Second
+		if err := g.Kick(playerID, req.Target, duration, req.Reason); err != nil {
+			writeActionError(w, err)
+			return
+		}
+		// The game has already closed their streams; make sure they
+		// cannot act or reconnect with tokens they still hold
+		s.tokens.revokeSubject(playerSubject(g.ID, req.Target))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Player kicked",
+		})
+
+	case "report":
+		if err := g.Report(playerID, req.Target, req.EventIDs, req.Reason); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Report recorded",
+		})
+
+	case "pass":
+		if err := g.PassTurn(playerID); err != nil {
+			writeActionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Turn passed",
+		})
+
 	default:
 		http.Error(w, "Unknown action", http.StatusBadRequest)
 	}
 }
 
-func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request, g *game.Game) {
+func (s *Server) handleChatHistory(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-		return
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	messages := g.ChatHistory(claims.PlayerID, r.URL.Query().Get("since"), limit)
+	if messages == nil {
+		messages = []game.Event{}
 	}
 
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+func (s *Server) handleQuests(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	claims, err := s.validateToken(parts[1])
-	if err != nil {
-		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+	quests, progress := g.QuestLog(claims.PlayerID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"quests":   quests,
+		"progress": progress,
+	})
+}
+
+func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if claims.GameID != g.ID {
-		http.Error(w, "Token not valid for this game", http.StatusForbidden)
+	since := 0
+	if v := r.URL.Query().Get("since"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "since must be an integer", http.StatusBadRequest)
+			return
+		}
+		since = n
+	}
+
+	entries := g.AuditLog(game.AuditKind(r.URL.Query().Get("kind")), since)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// writeActionError reports a failed action. Cooldown rejections become a
+// 429 carrying a machine-readable retry delay; anything else is a 400.
+func writeActionError(w http.ResponseWriter, err error) {
+	var cooldownErr *game.CooldownError
+	if errors.As(err, &cooldownErr) {
+		writeRetryAfter(w, err.Error(), cooldownErr.Action, cooldownErr.RetryAfter)
 		return
 	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
 
-	playerID := claims.PlayerID
+func writeRetryAfter(w http.ResponseWriter, message string, action game.Action, wait time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
 
-	player := g.GetPlayer(playerID)
-	if player == nil {
-		http.Error(w, "Player not found", http.StatusNotFound)
+	response := map[string]interface{}{
+		"error":          message,
+		"retry_after_ms": wait.Milliseconds(),
+	}
+	if action != "" {
+		response["action"] = action
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	var eventChan chan game.Event
+	var welcomeEvent game.Event
+
+	if !claims.hasRole(RolePlayer) {
+		// Spectators and admins watch the whole game, so give them more
+		// room to fall behind
+		eventChan = make(chan game.Event, 50)
+		g.AddSpectator(eventChan, claims.Subject, claims.hasRole(RoleAdmin))
+		welcomeEvent = game.Event{
+			Type:      "connected",
+			Message:   fmt.Sprintf("Spectating game %s", g.ID),
+			Timestamp: time.Now(),
+		}
+	} else {
+		playerID := claims.PlayerID
+
+		player := g.GetPlayer(playerID)
+		if player == nil {
+			http.Error(w, "Player not found", http.StatusNotFound)
+			return
+		}
+
+		eventChan = make(chan game.Event, 10)
+		g.AddClient(eventChan, playerID) // Pass playerID
+		welcomeEvent = game.Event{
+			Type:      "connected",
+			Message:   fmt.Sprintf("Connected to game. You are in %s", player.CurrentLocation),
+			Location:  player.CurrentLocation,
+			Timestamp: time.Now(),
+		}
+	}
+	defer g.RemoveClient(eventChan)
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	eventChan := make(chan game.Event, 10)
-	g.AddClient(eventChan, playerID) // Pass playerID
-	defer g.RemoveClient(eventChan)
-
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
 
-	welcomeEvent := game.Event{
-		Type:      "connected",
-		Message:   fmt.Sprintf("Connected to game. You are in %s", player.CurrentLocation),
-		Location:  player.CurrentLocation,
-		Timestamp: time.Now(),
-	}
 	data, _ := json.Marshal(welcomeEvent)
 	w.Write([]byte("data: "))
 	w.Write(data)
@@ -398,7 +913,12 @@ func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request, g *game.Game)
 
 	for {
 		select {
-		case event := <-eventChan:
+		case event, ok := <-eventChan:
+			if !ok {
+				// The game dropped this client
+				return
+			}
+
 			data, err := json.Marshal(event)
 			if err != nil {
 				continue
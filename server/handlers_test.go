@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetGameHidesPlayerDetails(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, nil)
+	id, _ := joinTestGame(t, s, g, "alice")
+
+	g.Mu.Lock()
+	g.Players[id].Hidden = true
+	g.Mu.Unlock()
+
+	w := do(t, s, http.MethodGet, "/games/"+g.ID, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Players map[string]map[string]interface{} `json:"players"`
+	}
+	decode(t, w, &resp)
+
+	player := resp.Players[id]
+	if player == nil {
+		t.Fatalf("player %s missing from %v", id, resp.Players)
+	}
+	if player["name"] != "alice" {
+		t.Errorf("name = %v, want alice", player["name"])
+	}
+	for _, field := range []string{"current_location", "hidden", "inventory", "effects", "account_id"} {
+		if _, ok := player[field]; ok {
+			t.Errorf("public view includes %q", field)
+		}
+	}
+}
+
+func TestPlayerContextOccupancy(t *testing.T) {
+	tests := []struct {
+		name   string
+		hidden bool
+		want   float64
+	}{
+		{"visible player counted", false, 2},
+		{"hidden player not counted", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			g := createTestGame(t, s, nil)
+			a, token := joinTestGame(t, s, g, "alice")
+			b, _ := joinTestGame(t, s, g, "bob")
+
+			g.Mu.Lock()
+			g.Players[b].CurrentLocation = g.Players[a].CurrentLocation
+			g.Players[b].Hidden = tt.hidden
+			g.Mu.Unlock()
+
+			w := do(t, s, http.MethodGet, "/games/"+g.ID+"/players/me", token, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			var resp struct {
+				CurrentLocation struct {
+					Occupancy float64 `json:"occupancy"`
+				} `json:"current_location"`
+				PlayersHere []interface{} `json:"players_here"`
+			}
+			decode(t, w, &resp)
+
+			if resp.CurrentLocation.Occupancy != tt.want {
+				t.Errorf("occupancy = %v, want %v", resp.CurrentLocation.Occupancy, tt.want)
+			}
+			if got := len(resp.PlayersHere); got != int(tt.want)-1 {
+				t.Errorf("players_here has %d players, want %d", got, int(tt.want)-1)
+			}
+		})
+	}
+}
+
+func TestSpectators(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		withInvite bool
+		want       int
+	}{
+		{"public game", "public", false, http.StatusCreated},
+		{"private game without invite", "private", false, http.StatusForbidden},
+		{"private game with invite", "private", true, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			w := do(t, s, http.MethodPost, "/games", "", map[string]string{"visibility": tt.visibility})
+			if w.Code != http.StatusCreated {
+				t.Fatalf("creating game: %d %s", w.Code, w.Body.String())
+			}
+			var created struct {
+				GameID     string `json:"game_id"`
+				InviteCode string `json:"invite_code"`
+			}
+			decode(t, w, &created)
+
+			body := map[string]string{}
+			if tt.withInvite {
+				body["invite_code"] = created.InviteCode
+			}
+			w = do(t, s, http.MethodPost, "/games/"+created.GameID+"/spectators", "", body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			// A spectator watches but cannot act
+			var resp struct {
+				Token string `json:"token"`
+			}
+			decode(t, w, &resp)
+			w = do(t, s, http.MethodPost, "/games/"+created.GameID+"/actions", resp.Token, map[string]string{"action": "search"})
+			if w.Code != http.StatusForbidden {
+				t.Errorf("spectator action status = %d, want 403", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"game-api/game"
+)
+
+// joinCode picks the password or invite code a request presents. A signed
+// invite link (?invite=...) takes precedence over codes in the body.
+func (s *Server) joinCode(r *http.Request, g *game.Game, password, inviteCode string) (string, error) {
+	if link := r.URL.Query().Get("invite"); link != "" {
+		claims, err := s.validateInviteToken(link)
+		if err != nil || claims.GameID != g.ID {
+			return "", fmt.Errorf("invalid or expired invite link")
+		}
+		return claims.Code, nil
+	}
+	if password != "" {
+		return password, nil
+	}
+	return inviteCode, nil
+}
+
+// inviteView is an invite as shown to the owner, with its signed link.
+type inviteView struct {
+	game.Invite
+	Link string `json:"link"`
+}
+
+// viewInvite signs a fresh link for invite with the current key, so listing
+// invites hands out links good for another InviteLinkTTL.
+func (s *Server) viewInvite(g *game.Game, invite game.Invite) (inviteView, error) {
+	token, err := s.generateInviteToken(g.ID, invite)
+	if err != nil {
+		return inviteView{}, err
+	}
+	return inviteView{Invite: invite, Link: fmt.Sprintf("/games/%s/players?invite=%s", g.ID, token)}, nil
+}
+
+// handleInvites lists (GET) or creates (POST) invites.
+func (s *Server) handleInvites(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims) {
+	switch r.Method {
+	case http.MethodGet:
+		invites, err := g.Invites(actorOf(claims))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		views := make([]inviteView, 0, len(invites))
+		for _, invite := range invites {
+			view, err := s.viewInvite(g, invite)
+			if err != nil {
+				http.Error(w, "Failed to sign invite link", http.StatusInternalServerError)
+				return
+			}
+			views = append(views, view)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"invites": views,
+			"count":   len(views),
+		})
+
+	case http.MethodPost:
+		// The body is optional; an empty one makes an unlimited,
+		// non-expiring invite
+		var req struct {
+			TTLSeconds int `json:"ttl_seconds"`
+			MaxUses    int `json:"max_uses"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		invite, err := g.CreateInvite(actorOf(claims), time.Duration(req.TTLSeconds)*time.Second, req.MaxUses)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		view, err := s.viewInvite(g, invite)
+		if err != nil {
+			http.Error(w, "Failed to sign invite link", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(view)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRevokeInvite revokes an invite by code.
+func (s *Server) handleRevokeInvite(w http.ResponseWriter, r *http.Request, g *game.Game, claims *Claims, code string) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := g.RevokeInvite(actorOf(claims), code); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Invite revoked",
+	})
+}
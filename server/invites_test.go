@@ -0,0 +1,150 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"game-api/game"
+)
+
+// createInviteGame creates an invite-only game, joins its owner with the
+// first invite and returns the game ID and the owner's token.
+func createInviteGame(t *testing.T, s *Server) (string, string) {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/games", "", map[string]interface{}{"invite_only": true})
+	var created struct {
+		GameID     string `json:"game_id"`
+		InviteCode string `json:"invite_code"`
+	}
+	decode(t, w, &created)
+
+	w = do(t, s, http.MethodPost, "/games/"+created.GameID+"/players", "", map[string]string{"name": "owner", "invite_code": created.InviteCode})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("joining owner: %d %s", w.Code, w.Body.String())
+	}
+	var joined struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &joined)
+	return created.GameID, joined.Token
+}
+
+// createInviteLink creates a single-use invite and returns its link.
+func createInviteLink(t *testing.T, s *Server, gameID, token string) inviteView {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/games/"+gameID+"/invites", token, map[string]int{"max_uses": 1})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating invite: %d %s", w.Code, w.Body.String())
+	}
+	var view inviteView
+	decode(t, w, &view)
+	return view
+}
+
+func TestInviteLinks(t *testing.T) {
+	s := newTestServer(t)
+	gameID, ownerToken := createInviteGame(t, s)
+	link := createInviteLink(t, s, gameID, ownerToken).Link
+	otherID, otherOwner := createInviteGame(t, s)
+	otherLink := createInviteLink(t, s, otherID, otherOwner).Link
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"no invite", "/games/" + gameID + "/players", http.StatusForbidden},
+		{"garbage link", "/games/" + gameID + "/players?invite=garbage", http.StatusForbidden},
+		{"link for another game", "/games/" + gameID + "/players?" + strings.SplitN(otherLink, "?", 2)[1], http.StatusForbidden},
+		{"valid link", link, http.StatusCreated},
+		{"used up link", link, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, http.MethodPost, tt.path, "", map[string]string{"name": "guest"})
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInviteRoutesNeedOwner(t *testing.T) {
+	s := newTestServer(t)
+	gameID, ownerToken := createInviteGame(t, s)
+	view := createInviteLink(t, s, gameID, ownerToken)
+	w := do(t, s, http.MethodPost, view.Link, "", map[string]string{"name": "guest"})
+	var guest struct {
+		Token string `json:"token"`
+	}
+	decode(t, w, &guest)
+	guestToken := guest.Token
+
+	invites := "/games/" + gameID + "/invites"
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"guest lists invites", http.MethodGet, invites, guestToken, http.StatusForbidden},
+		{"guest revokes invite", http.MethodDelete, invites + "/" + view.Code, guestToken, http.StatusForbidden},
+		{"no token", http.MethodGet, invites, "", http.StatusUnauthorized},
+		{"owner revokes unknown invite", http.MethodDelete, invites + "/nope", ownerToken, http.StatusNotFound},
+		{"owner revokes invite", http.MethodDelete, invites + "/" + view.Code, ownerToken, http.StatusOK},
+		{"owner lists invites", http.MethodGet, invites, ownerToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, tt.method, tt.path, tt.token, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInviteLinkExpiry(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	tests := []struct {
+		name   string
+		invite game.Invite
+		want   time.Time
+	}{
+		{"invite without expiry", game.Invite{Code: "a"}, now.Add(InviteLinkTTL)},
+		{"invite expiring first", game.Invite{Code: "b", ExpiresAt: now.Add(time.Hour)}, now.Add(time.Hour)},
+		{"invite expiring later", game.Invite{Code: "c", ExpiresAt: now.Add(2 * InviteLinkTTL)}, now.Add(InviteLinkTTL)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.generateInviteToken("g", tt.invite)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := s.validateInviteToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := claims.ExpiresAt.Sub(tt.want); d < -time.Second || d > time.Second {
+				t.Errorf("link expires at %v, want %v", claims.ExpiresAt, tt.want)
+			}
+		})
+	}
+
+	// A key that signed only invite links still retires
+	if err := s.keys.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range s.keys.keys {
+		if k != s.keys.current && k.retireAt.IsZero() {
+			t.Errorf("rotated-out key %s is never retired", k.id)
+		}
+	}
+}
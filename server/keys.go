@@ -0,0 +1,364 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"game-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const rsaKeyBits = 2048
+
+// signingKey is one entry in the keyring. HMAC keys verify with the same
+// secret they sign with; asymmetric keys also publish their public half.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   interface{} // []byte, ed25519.PrivateKey or *rsa.PrivateKey; nil if verify-only
+	public    interface{} // []byte, ed25519.PublicKey or *rsa.PublicKey
+	createdAt time.Time
+	retireAt  time.Time // zero while it should keep verifying indefinitely
+
+	// lastExpiry is the latest expiry of anything the key signed, so it
+	// keeps verifying that long once it is rotated out
+	lastExpiry time.Time
+}
+
+// signed records that the key signed claims. The caller must hold kr.mu.
+func (k *signingKey) signed(claims jwt.Claims) {
+	exp, err := claims.GetExpirationTime()
+	if err == nil && exp != nil && exp.After(k.lastExpiry) {
+		k.lastExpiry = exp.Time
+	}
+}
+
+// keyring signs tokens with its current key and verifies them with any key
+// it still holds, found by the token's kid header. Rotating moves signing to
+// a fresh key while older keys keep verifying until everything they signed
+// has expired, so nobody is logged out and no invite link breaks. Everything
+// the server signs expires, invite links after at most InviteLinkTTL, so
+// every rotated-out key is eventually retired.
+//
+// Tokens issued before kid headers existed are verified with the legacy
+// key, the JWT_SECRET HMAC key, while that is configured.
+//
+// Generated keys live only in this process. Deployments with several
+// instances should load the same key from config and publish rotations
+// themselves.
+type keyring struct {
+	mu      sync.RWMutex
+	method  jwt.SigningMethod
+	current *signingKey
+	keys    map[string]*signingKey
+	legacy  *signingKey // verifies tokens with no kid; nil without JWT_SECRET
+}
+
+// newKeyring builds the keyring from config: the signing key for the
+// configured algorithm, plus any previous HMAC secrets as verify-only keys.
+func newKeyring(cfg *config.Config) (*keyring, error) {
+	kr := &keyring{keys: make(map[string]*signingKey)}
+
+	switch cfg.JWTAlgorithm {
+	case "", "HS256":
+		kr.method = jwt.SigningMethodHS256
+		kr.current = hmacKey(cfg.JWTSecret)
+	case "EdDSA", "RS256":
+		kr.method = jwt.GetSigningMethod(cfg.JWTAlgorithm)
+		if cfg.JWTPrivateKeyFile == "" {
+			key, err := kr.generate()
+			if err != nil {
+				return nil, err
+			}
+			kr.current = key
+			log.Printf("WARNING: No JWT_PRIVATE_KEY_FILE set. Generated a temporary %s key %s", cfg.JWTAlgorithm, key.id)
+			break
+		}
+		key, err := loadPrivateKey(cfg.JWTPrivateKeyFile, kr.method)
+		if err != nil {
+			return nil, err
+		}
+		kr.current = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q: must be HS256, EdDSA or RS256", cfg.JWTAlgorithm)
+	}
+	kr.keys[kr.current.id] = kr.current
+
+	for _, secret := range cfg.JWTPreviousSecrets {
+		key := hmacKey(secret)
+		key.private = nil
+		kr.keys[key.id] = key
+	}
+
+	if len(cfg.JWTSecret) > 0 {
+		kr.legacy = hmacKey(cfg.JWTSecret)
+		kr.legacy.private = nil
+	}
+	return kr, nil
+}
+
+// fingerprint derives a stable kid from key material, so a key loaded from
+// config keeps its kid across restarts and verifiers can cache it.
+func fingerprint(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+func hmacKey(secret []byte) *signingKey {
+	return &signingKey{
+		id:        fingerprint(append([]byte("hmac:"), secret...)),
+		method:    jwt.SigningMethodHS256,
+		private:   secret,
+		public:    secret,
+		createdAt: time.Now(),
+	}
+}
+
+func asymmetricKey(private crypto.Signer, method jwt.SigningMethod) (*signingKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{
+		id:        fingerprint(der),
+		method:    method,
+		private:   private,
+		public:    private.Public(),
+		createdAt: time.Now(),
+	}, nil
+}
+
+// loadPrivateKey reads a PKCS#8 PEM key (or PKCS#1 for RSA) and checks it
+// suits the configured algorithm.
+func loadPrivateKey(path string, method jwt.SigningMethod) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch key := parsed.(type) {
+	case ed25519.PrivateKey:
+		if method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("%s: an Ed25519 key needs JWT_ALGORITHM=EdDSA", path)
+		}
+		return asymmetricKey(key, method)
+	case *rsa.PrivateKey:
+		if method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("%s: an RSA key needs JWT_ALGORITHM=RS256", path)
+		}
+		if key.N.BitLen() < rsaKeyBits {
+			return nil, fmt.Errorf("%s: RSA keys must be at least %d bits", path, rsaKeyBits)
+		}
+		return asymmetricKey(key, method)
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, parsed)
+	}
+}
+
+// generate creates a new key for the keyring's algorithm.
+func (kr *keyring) generate() (*signingKey, error) {
+	switch kr.method {
+	case jwt.SigningMethodEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return asymmetricKey(private, kr.method)
+	case jwt.SigningMethodRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		return asymmetricKey(private, kr.method)
+	default:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return hmacKey(secret), nil
+	}
+}
+
+// rotate makes a fresh key current. The old one keeps verifying until the
+// last thing it signed expires, and keys past their retirement are dropped.
+func (kr *keyring) rotate() error {
+	key, err := kr.generate()
+	if err != nil {
+		return err
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	now := time.Now()
+	old := kr.current
+	old.retireAt = now
+	if old.lastExpiry.After(now) {
+		old.retireAt = old.lastExpiry
+	}
+	kr.current = key
+	kr.keys[key.id] = key
+
+	for id, k := range kr.keys {
+		if !k.retireAt.IsZero() && now.After(k.retireAt) {
+			delete(kr.keys, id)
+		}
+	}
+	return nil
+}
+
+// rotateEvery rotates the signing key on a schedule. It never returns.
+func (kr *keyring) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := kr.rotate(); err != nil {
+			log.Printf("Failed to rotate JWT signing key: %v", err)
+			continue
+		}
+		kr.mu.RLock()
+		log.Printf("Rotated JWT signing key to %s", kr.current.id)
+		kr.mu.RUnlock()
+	}
+}
+
+// sign signs claims with the current key and names it in the kid header.
+func (kr *keyring) sign(claims jwt.Claims) (string, error) {
+	kr.mu.Lock()
+	key := kr.current
+	key.signed(claims)
+	kr.mu.Unlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// keyFunc finds the verification key for a token. The token's algorithm
+// must be the one its key was made for, so an HMAC secret can never be
+// used to check a token that names an asymmetric key, or the reverse.
+func (kr *keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	// rotate sets retireAt under kr.mu, so copy what is needed before
+	// letting go of it
+	kr.mu.RLock()
+	key := kr.keys[kid]
+	if kid == "" {
+		key = kr.legacy
+	}
+	var retireAt time.Time
+	var method jwt.SigningMethod
+	var public interface{}
+	if key != nil {
+		retireAt, method, public = key.retireAt, key.method, key.public
+	}
+	kr.mu.RUnlock()
+
+	if key == nil && kid == "" {
+		return nil, fmt.Errorf("token has no key ID")
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+	if !retireAt.IsZero() && time.Now().After(retireAt) {
+		return nil, fmt.Errorf("signing key %s has been retired", kid)
+	}
+	if token.Method.Alg() != method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return public, nil
+}
+
+// JWK is one public key in a JSON Web Key Set (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // OKP
+	X         string `json:"x,omitempty"`   // OKP
+	N         string `json:"n,omitempty"`   // RSA
+	E         string `json:"e,omitempty"`   // RSA
+}
+
+// jwks lists the public half of every asymmetric key that still verifies.
+// HMAC secrets are never published.
+func (kr *keyring) jwks() []JWK {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	enc := base64.RawURLEncoding
+	keys := make([]JWK, 0, len(kr.keys))
+	for _, k := range kr.keys {
+		if !k.retireAt.IsZero() && time.Now().After(k.retireAt) {
+			continue
+		}
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = enc.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = enc.EncodeToString(pub.N.Bytes())
+			jwk.E = enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].KeyID < keys[j].KeyID })
+	return keys
+}
+
+// handleJWKS serves GET /.well-known/jwks.json so other services can verify
+// tokens without sharing a secret. The set is empty when tokens are signed
+// with HMAC.
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Verifiers refetch on an unknown kid, so a short cache is enough
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": s.keys.jwks(),
+	})
+}
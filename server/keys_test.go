@@ -0,0 +1,223 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"game-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeEd25519Key writes a PKCS#8 Ed25519 key and returns its path.
+func writeEd25519Key(t *testing.T) string {
+	t.Helper()
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testClaims expire after ttl.
+func testClaims(ttl time.Duration) jwt.Claims {
+	return jwt.RegisteredClaims{Subject: "p", ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl))}
+}
+
+func verify(kr *keyring, token string) error {
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, kr.keyFunc)
+	return err
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	for _, cfg := range []config.Config{
+		{JWTAlgorithm: "HS512"},
+		{JWTAlgorithm: "EdDSA", JWTPrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{JWTAlgorithm: "RS256", JWTPrivateKeyFile: writeEd25519Key(t)},
+	} {
+		if _, err := newKeyring(&cfg); err == nil {
+			t.Errorf("newKeyring accepted %+v", cfg)
+		}
+	}
+}
+
+func TestKeyringSignAndVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{"HS256", config.Config{JWTSecret: []byte("secret")}},
+		{"generated EdDSA", config.Config{JWTAlgorithm: "EdDSA"}},
+		{"EdDSA from file", config.Config{JWTAlgorithm: "EdDSA", JWTPrivateKeyFile: writeEd25519Key(t)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kr, err := newKeyring(&tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := kr.sign(testClaims(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verify(kr, token); err != nil {
+				t.Errorf("verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyringKeyIDs(t *testing.T) {
+	kr, err := newKeyring(&config.Config{JWTAlgorithm: "EdDSA", JWTSecret: []byte("secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmac := func(kid, secret string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(time.Minute))
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, _ := token.SignedString([]byte(secret))
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"legacy token without kid", hmac("", "secret"), true},
+		{"legacy token with the wrong secret", hmac("", "other"), false},
+		{"unknown kid", hmac("nope", "secret"), false},
+		{"HMAC token naming an EdDSA key", hmac(kr.current.id, "secret"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(kr, tt.token); (err == nil) != tt.ok {
+				t.Errorf("verify = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestKeyringPreviousSecrets(t *testing.T) {
+	old, _ := newKeyring(&config.Config{JWTSecret: []byte("old")})
+	token, _ := old.sign(testClaims(time.Minute))
+
+	kr, err := newKeyring(&config.Config{JWTSecret: []byte("new"), JWTPreviousSecrets: [][]byte{[]byte("old")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(kr, token); err != nil {
+		t.Errorf("token signed with a previous secret: %v", err)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	kr, err := newKeyring(&config.Config{JWTAlgorithm: "EdDSA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := kr.current
+	token, _ := kr.sign(testClaims(time.Hour))
+
+	if err := kr.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(kr, token); err != nil {
+		t.Errorf("token signed before rotating: %v", err)
+	}
+	if old.retireAt.Before(time.Now().Add(time.Hour - time.Second)) {
+		t.Errorf("old key retires at %v, before its token expires", old.retireAt)
+	}
+	if got := len(kr.jwks()); got != 2 {
+		t.Errorf("jwks has %d keys during the overlap, want 2", got)
+	}
+
+	// Once retired, the key stops verifying and is dropped on the next
+	// rotation
+	old.retireAt = time.Now().Add(-time.Second)
+	if err := verify(kr, token); err == nil {
+		t.Error("retired key still verifies")
+	}
+	if err := kr.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, kept := kr.keys[old.id]; kept {
+		t.Error("retired key kept")
+	}
+}
+
+func TestKeyringRotateWhileVerifying(t *testing.T) {
+	kr, err := newKeyring(&config.Config{JWTAlgorithm: "EdDSA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := kr.sign(testClaims(time.Hour))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			kr.rotate()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if err := verify(kr, token); err != nil {
+			t.Errorf("verify during rotation: %v", err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestInviteLinksOutliveRotation(t *testing.T) {
+	s := newTestServer(t)
+	gameID, ownerToken := createInviteGame(t, s)
+	link := createInviteLink(t, s, gameID, ownerToken).Link
+
+	for i := 0; i < 3; i++ {
+		if err := s.keys.rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := do(t, s, http.MethodPost, link, "", map[string]string{"name": "guest"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("joining with a link signed before rotating: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleJWKS(t *testing.T) {
+	s := newTestServer(t)
+	w := do(t, s, http.MethodGet, "/.well-known/jwks.json", "", nil)
+	var resp struct {
+		Keys []JWK `json:"keys"`
+	}
+	decode(t, w, &resp)
+	// HMAC keys are never published
+	if resp.Keys == nil || len(resp.Keys) != 0 {
+		t.Errorf("keys = %v, want an empty list", resp.Keys)
+	}
+
+	kr, _ := newKeyring(&config.Config{JWTAlgorithm: "EdDSA"})
+	keys := kr.jwks()
+	if len(keys) != 1 || keys[0].KeyID != kr.current.id || keys[0].KeyType != "OKP" || keys[0].X == "" {
+		t.Errorf("jwks = %+v", keys)
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"game-api/game"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type listSort string
+
+const (
+	sortCreatedAt   listSort = "created_at"
+	sortPlayerCount listSort = "player_count"
+)
+
+// listQuery is a parsed GET /games request.
+type listQuery struct {
+	Limit int
+	Sort  listSort
+	Desc  bool
+	After *listCursor
+
+	State string
+	Open  *bool
+	Tag   string
+	Owner string
+}
+
+// listCursor marks the last game of a page by its creation time and ID.
+// Pages resume strictly after that position, so games added or removed
+// between requests never shift a page, and since creation times never
+// change, no game is repeated or skipped. Only created_at listings page: a
+// game's player count changes as players come and go, so a player_count
+// cursor could repeat or skip games. sort=player_count returns a single
+// page instead.
+type listCursor struct {
+	Desc      bool      `json:"d"`
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+func parseListQuery(r *http.Request) (listQuery, error) {
+	values := r.URL.Query()
+	q := listQuery{
+		Limit: defaultPageSize,
+		Sort:  sortCreatedAt,
+		Desc:  true,
+		State: values.Get("state"),
+		Tag:   strings.ToLower(values.Get("tag")),
+		Owner: values.Get("owner"),
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		q.Limit = limit
+	}
+
+	switch v := listSort(values.Get("sort")); v {
+	case "":
+	case sortCreatedAt, sortPlayerCount:
+		q.Sort = v
+	default:
+		return q, fmt.Errorf("sort must be created_at or player_count")
+	}
+
+	switch values.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Desc = false
+	default:
+		return q, fmt.Errorf("order must be asc or desc")
+	}
+
+	switch game.State(q.State) {
+	case "", game.StateWaiting, game.StateActive, game.StateFinished:
+	default:
+		return q, fmt.Errorf("unknown state: %s", q.State)
+	}
+
+	if v := values.Get("open"); v != "" {
+		open, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("open must be true or false")
+		}
+		q.Open = &open
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			return q, err
+		}
+		if q.Sort != sortCreatedAt {
+			return q, fmt.Errorf("only sort=created_at listings have more pages")
+		}
+		// A cursor only makes sense in the ordering that produced it
+		if cursor.Desc != q.Desc {
+			return q, fmt.Errorf("cursor does not match order")
+		}
+		q.After = cursor
+	}
+
+	return q, nil
+}
+
+func (q listQuery) matches(g GameSummary) bool {
+	if q.State != "" && string(g.State) != q.State {
+		return false
+	}
+	if q.Open != nil && g.OpenSlots != *q.Open {
+		return false
+	}
+	if q.Tag != "" && !containsString(g.Tags, q.Tag) {
+		return false
+	}
+	if q.Owner != "" && g.OwnerID != q.Owner && !strings.EqualFold(g.OwnerName, q.Owner) {
+		return false
+	}
+	return true
+}
+
+// less orders a before b. Ties on the sort key fall back to creation time
+// and then ID, so the order is total and cursors are unambiguous.
+func (q listQuery) less(a, b GameSummary) bool {
+	cmp := 0
+	switch {
+	case q.Sort == sortPlayerCount && a.PlayerCount != b.PlayerCount:
+		cmp = a.PlayerCount - b.PlayerCount
+	case !a.CreatedAt.Equal(b.CreatedAt):
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	default:
+		cmp = strings.Compare(a.ID, b.ID)
+	}
+	if q.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func (q listQuery) cursor(g GameSummary) listCursor {
+	return listCursor{Desc: q.Desc, CreatedAt: g.CreatedAt, ID: g.ID}
+}
+
+// game is the position the cursor marks, for comparing with less.
+func (c listCursor) game() GameSummary {
+	return GameSummary{CreatedAt: c.CreatedAt, ID: c.ID}
+}
+
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// listGames returns one page of games matching the query's filters. The
+// next_cursor field is set when more games follow in a created_at listing.
+func (s *Server) listGames(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var matched []GameSummary
+	for _, summary := range s.gameSummaries() {
+		if q.matches(summary) {
+			matched = append(matched, summary)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return q.less(matched[i], matched[j])
+	})
+
+	start := 0
+	if q.After != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return q.less(q.After.game(), matched[i])
+		})
+	}
+
+	page := matched[start:min(start+q.Limit, len(matched))]
+	if page == nil {
+		page = []GameSummary{}
+	}
+
+	response := map[string]interface{}{
+		"games": page,
+		"count": len(page),
+		"total": len(matched),
+	}
+	if q.Sort == sortCreatedAt && start+len(page) < len(matched) {
+		response["next_cursor"] = q.cursor(page[len(page)-1]).encode()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
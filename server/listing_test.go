@@ -0,0 +1,179 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseListQueryErrors(t *testing.T) {
+	ascending := listCursor{}.encode()
+	descending := listCursor{Desc: true}.encode()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"limit zero", "limit=0", "limit must be between 1 and 100"},
+		{"limit too large", "limit=101", "limit must be between 1 and 100"},
+		{"limit not a number", "limit=ten", "limit must be between 1 and 100"},
+		{"unknown sort", "sort=name", "sort must be created_at or player_count"},
+		{"unknown order", "order=up", "order must be asc or desc"},
+		{"unknown state", "state=paused", "unknown state: paused"},
+		{"open not a bool", "open=maybe", "open must be true or false"},
+		{"cursor not base64", "cursor=!!!", "invalid cursor"},
+		{"cursor not json", "cursor=" + url.QueryEscape("bm9wZQ"), "invalid cursor"},
+		{"cursor from another order", "cursor=" + ascending, "cursor does not match order"},
+		{"cursor with player_count", "sort=player_count&cursor=" + descending, "only sort=created_at listings have more pages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/games?"+tt.query, nil)
+			_, err := parseListQuery(r)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQueryLess(t *testing.T) {
+	now := time.Now()
+	older := GameSummary{CreatedAt: now.Add(-time.Minute), PlayerCount: 3, ID: "b"}
+	newer := GameSummary{CreatedAt: now, PlayerCount: 1, ID: "a"}
+	twin := GameSummary{CreatedAt: now, PlayerCount: 1, ID: "c"}
+
+	tests := []struct {
+		name string
+		q    listQuery
+		a, b GameSummary
+		want bool
+	}{
+		{"newest first", listQuery{Sort: sortCreatedAt, Desc: true}, newer, older, true},
+		{"oldest first", listQuery{Sort: sortCreatedAt}, older, newer, true},
+		{"most players first", listQuery{Sort: sortPlayerCount, Desc: true}, older, newer, true},
+		{"fewest players first", listQuery{Sort: sortPlayerCount}, newer, older, true},
+		{"ties broken by ID", listQuery{Sort: sortPlayerCount}, newer, twin, true},
+		{"order is strict", listQuery{Sort: sortCreatedAt}, newer, newer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.less(tt.a, tt.b); got != tt.want {
+				t.Errorf("less = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListGamesPages(t *testing.T) {
+	s := newTestServer(t)
+	for i := 0; i < 5; i++ {
+		createTestGame(t, s, nil)
+	}
+	createTestGame(t, s, map[string]interface{}{"visibility": "unlisted"})
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("too many pages")
+		}
+		path := "/games?limit=2"
+		if cursor != "" {
+			path += "&cursor=" + cursor
+		}
+		w := do(t, s, http.MethodGet, path, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Games      []GameSummary `json:"games"`
+			Total      int           `json:"total"`
+			NextCursor string        `json:"next_cursor"`
+		}
+		decode(t, w, &resp)
+
+		if resp.Total != 5 {
+			t.Errorf("total = %d, want 5", resp.Total)
+		}
+		for _, g := range resp.Games {
+			if seen[g.ID] {
+				t.Errorf("game %s on two pages", g.ID)
+			}
+			seen[g.ID] = true
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("listed %d games, want 5", len(seen))
+	}
+}
+
+func TestListGamesByPlayerCount(t *testing.T) {
+	s := newTestServer(t)
+	busy := createTestGame(t, s, nil)
+	joinTestGame(t, s, busy, "alice")
+	for i := 0; i < 2; i++ {
+		createTestGame(t, s, nil)
+	}
+
+	w := do(t, s, http.MethodGet, "/games?sort=player_count&limit=2", "", nil)
+	var resp struct {
+		Games      []GameSummary `json:"games"`
+		Total      int           `json:"total"`
+		NextCursor string        `json:"next_cursor"`
+	}
+	decode(t, w, &resp)
+	if len(resp.Games) != 2 || resp.Games[0].ID != busy.ID || resp.Total != 3 {
+		t.Errorf("got %d of %d games, want the busiest first", len(resp.Games), resp.Total)
+	}
+	if resp.NextCursor != "" {
+		t.Error("player_count listing has a next cursor")
+	}
+}
+
+func TestListGamesFilters(t *testing.T) {
+	s := newTestServer(t)
+	tagged := createTestGame(t, s, map[string]interface{}{"tags": []string{"pvp"}})
+	createTestGame(t, s, nil)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"tag=pvp", 1},
+		{"tag=PVP", 1},
+		{"tag=coop", 0},
+		{"state=waiting", 2},
+		{"state=finished", 0},
+		{"open=true", 2},
+		{"open=false", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := do(t, s, http.MethodGet, "/games?"+tt.query, "", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+			}
+			var resp struct {
+				Games []GameSummary `json:"games"`
+			}
+			decode(t, w, &resp)
+			if len(resp.Games) != tt.want {
+				t.Errorf("got %d games, want %d", len(resp.Games), tt.want)
+			}
+			if tt.query == "tag=pvp" && len(resp.Games) == 1 && resp.Games[0].ID != tagged.ID {
+				t.Errorf("got game %s, want %s", resp.Games[0].ID, tagged.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"game-api/game"
+)
+
+// LobbyThrottle is the shortest interval between game_updated events for
+// any one game. Changes in between are coalesced into one update.
+const LobbyThrottle = time.Second
+
+type LobbyEventType string
+
+const (
+	LobbyConnected   LobbyEventType = "connected"
+	LobbyGameCreated LobbyEventType = "game_created"
+	LobbyGameUpdated LobbyEventType = "game_updated"
+	LobbyGameRemoved LobbyEventType = "game_removed"
+)
+
+// LobbyEvent is sent to lobby clients when the game list changes. Game is
+// omitted for removals; Games carries the full list on connect.
+type LobbyEvent struct {
+	Type      LobbyEventType `json:"type"`
+	GameID    string         `json:"game_id,omitempty"`
+	Game      *GameSummary   `json:"game,omitempty"`
+	Games     []GameSummary  `json:"games,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// GameSummary is what the lobby shows about a game.
+type GameSummary struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	JoinPolicy    game.JoinPolicy `json:"join_policy"`
+	Tags          []string        `json:"tags,omitempty"`
+	Mode          game.Mode       `json:"mode"`
+	State         game.State      `json:"state"`
+	OwnerID       string          `json:"owner_id,omitempty"`
+	OwnerName     string          `json:"owner_name,omitempty"`
+	PlayerCount   int             `json:"player_count"`
+	MaxPlayers    int             `json:"max_players"` // 0 means no limit
+	OpenSlots     bool            `json:"open_slots"`
+	LocationCount int             `json:"location_count"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func summarize(g *game.Game) GameSummary {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+
+	summary := GameSummary{
+		ID:            g.ID,
+		Name:          g.Name,
+		JoinPolicy:    g.Join,
+		Tags:          g.Tags,
+		Mode:          g.Mode,
+		State:         g.State(),
+		OwnerID:       g.OwnerID,
+		PlayerCount:   len(g.Players),
+		MaxPlayers:    g.MaxPlayers,
+		OpenSlots:     g.OpenSlots(),
+		LocationCount: len(g.Locations),
+		CreatedAt:     g.CreatedAt,
+	}
+	if owner := g.Players[g.OwnerID]; owner != nil {
+		summary.OwnerName = owner.Name
+	}
+	return summary
+}
+
+// lobby fans game list changes out to lobby SSE clients. Creations and
+// removals go out immediately; updates are throttled per game.
+type lobby struct {
+	mu      sync.Mutex
+	clients map[chan LobbyEvent]bool
+	dirty   map[string]bool // games with an update waiting for the next flush
+	timer   *time.Timer     // pending flush, if any
+	lookup  func(id string) *game.Game
+}
+
+func newLobby(lookup func(id string) *game.Game) *lobby {
+	return &lobby{
+		clients: make(map[chan LobbyEvent]bool),
+		dirty:   make(map[string]bool),
+		lookup:  lookup,
+	}
+}
+
+func (l *lobby) subscribe(ch chan LobbyEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clients[ch] = true
+}
+
+func (l *lobby) unsubscribe(ch chan LobbyEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// publish drops and closes channels that fall behind
+	if l.clients[ch] {
+		delete(l.clients, ch)
+		close(ch)
+	}
+}
+
+func (l *lobby) publish(event LobbyEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.clients {
+		select {
+		case ch <- event:
+		default:
+			close(ch)
+			delete(l.clients, ch)
+		}
+	}
+}
+
+func (l *lobby) created(g *game.Game) {
+	if !g.Visibility.Listed() {
+		return
+	}
+	summary := summarize(g)
+	l.publish(LobbyEvent{Type: LobbyGameCreated, GameID: g.ID, Game: &summary})
+}
+
+func (l *lobby) removed(g *game.Game) {
+	l.mu.Lock()
+	delete(l.dirty, g.ID)
+	l.mu.Unlock()
+
+	if g.Visibility.Listed() {
+		l.publish(LobbyEvent{Type: LobbyGameRemoved, GameID: g.ID})
+	}
+}
+
+// updated schedules a game_updated event for the game at the next flush.
+func (l *lobby) updated(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.dirty[id] = true
+	if l.timer == nil {
+		l.timer = time.AfterFunc(LobbyThrottle, l.flush)
+	}
+}
+
+func (l *lobby) flush() {
+	l.mu.Lock()
+	ids := make([]string, 0, len(l.dirty))
+	for id := range l.dirty {
+		ids = append(ids, id)
+	}
+	l.dirty = make(map[string]bool)
+	l.timer = nil
+	l.mu.Unlock()
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		// The game may have been removed since it was marked
+		if g := l.lookup(id); g != nil && g.Visibility.Listed() {
+			summary := summarize(g)
+			l.publish(LobbyEvent{Type: LobbyGameUpdated, GameID: id, Game: &summary})
+		}
+	}
+}
+
+// handleLobbySSE streams game list changes, starting with a snapshot of
+// every game.
+func (s *Server) handleLobbySSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Subscribe before taking the snapshot so no change falls in between
+	eventChan := make(chan LobbyEvent, 20)
+	s.lobby.subscribe(eventChan)
+	defer s.lobby.unsubscribe(eventChan)
+
+	writeEvent := func(event LobbyEvent) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		w.Write([]byte("data: "))
+		w.Write(data)
+		w.Write([]byte("\n\n"))
+		flusher.Flush()
+	}
+
+	writeEvent(LobbyEvent{Type: LobbyConnected, Games: s.gameSummaries(), Timestamp: time.Now()})
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				// The lobby dropped this client
+				return
+			}
+			writeEvent(event)
+
+		case <-ticker.C:
+			w.Write([]byte(": keepalive\n\n"))
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// gameSummaries summarizes every listed game on the server.
+func (s *Server) gameSummaries() []GameSummary {
+	s.gamesMu.RLock()
+	games := make([]*game.Game, 0, len(s.games))
+	for _, g := range s.games {
+		if g.Visibility.Listed() {
+			games = append(games, g)
+		}
+	}
+	s.gamesMu.RUnlock()
+
+	summaries := make([]GameSummary, 0, len(games))
+	for _, g := range games {
+		summaries = append(summaries, summarize(g))
+	}
+	return summaries
+}
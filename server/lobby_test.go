@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+// drain returns every event waiting on ch.
+func drain(ch chan LobbyEvent) []LobbyEvent {
+	var events []LobbyEvent
+	for {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestLobbyPublishesListedGames(t *testing.T) {
+	tests := []struct {
+		visibility string
+		want       []LobbyEventType
+	}{
+		{"public", []LobbyEventType{LobbyGameCreated, LobbyGameRemoved}},
+		{"unlisted", nil},
+		{"private", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.visibility, func(t *testing.T) {
+			s := newTestServer(t)
+			ch := make(chan LobbyEvent, 10)
+			s.lobby.subscribe(ch)
+
+			g := createTestGame(t, s, map[string]interface{}{"visibility": tt.visibility})
+			s.removeGame(g.ID)
+
+			events := drain(ch)
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d: %+v", len(events), len(tt.want), events)
+			}
+			for i, e := range events {
+				if e.Type != tt.want[i] || e.GameID != g.ID {
+					t.Errorf("event %d = %s %s, want %s %s", i, e.Type, e.GameID, tt.want[i], g.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestLobbyCoalescesUpdates(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, nil)
+	gone := createTestGame(t, s, nil)
+
+	ch := make(chan LobbyEvent, 10)
+	s.lobby.subscribe(ch)
+
+	s.lobby.updated(g.ID)
+	s.lobby.updated(g.ID)
+	s.lobby.updated(gone.ID)
+	s.removeGame(gone.ID)
+	drain(ch)
+	s.lobby.flush()
+
+	events := drain(ch)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	if events[0].Type != LobbyGameUpdated || events[0].GameID != g.ID || events[0].Game == nil {
+		t.Errorf("event = %+v, want game_updated for %s with a summary", events[0], g.ID)
+	}
+}
+
+func TestLobbyDropsSlowClients(t *testing.T) {
+	s := newTestServer(t)
+	ch := make(chan LobbyEvent, 1)
+	s.lobby.subscribe(ch)
+
+	createTestGame(t, s, nil)
+	createTestGame(t, s, nil)
+
+	if e, ok := <-ch; !ok || e.Type != LobbyGameCreated {
+		t.Fatalf("first event = %+v, %v; want game_created", e, ok)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("slow client's channel still open")
+	}
+	// Unsubscribing a dropped client must not close its channel twice
+	s.lobby.unsubscribe(ch)
+}
+
+func TestLobbySSEMethod(t *testing.T) {
+	s := newTestServer(t)
+	if w := do(t, s, http.MethodPost, "/games/events", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", w.Code)
+	}
+}
@@ -0,0 +1,524 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"game-api/game"
+	"game-api/utils"
+)
+
+const (
+	// MatchSize is how many players a full match holds. Once the oldest
+	// ticket in a group has waited PartialMatchAfter, MinMatchSize will do.
+	MatchSize         = 4
+	MinMatchSize      = 2
+	PartialMatchAfter = 30 * time.Second
+
+	// QueueTimeout is how long a ticket waits before it expires unmatched.
+	QueueTimeout = 2 * time.Minute
+
+	// A ticket's skill window starts at its requested range and widens by
+	// SkillWidenPerSecond for every second it waits, up to MaxSkillWindow.
+	DefaultSkill        = 1000
+	DefaultSkillRange   = 100
+	SkillWidenPerSecond = 10
+	MaxSkillWindow      = 1000
+
+	matchInterval   = time.Second
+	ticketRetention = 5 * time.Minute // finished tickets stay readable this long
+)
+
+type TicketStatus string
+
+const (
+	TicketQueued    TicketStatus = "queued"
+	TicketMatched   TicketStatus = "matched"
+	TicketCancelled TicketStatus = "cancelled"
+	TicketExpired   TicketStatus = "expired"
+)
+
+// Match is where a matched ticket's player was placed.
+type Match struct {
+	GameID string       `json:"game_id"`
+	Player *game.Player `json:"player"`
+	tokenPair
+}
+
+// Ticket is one player's place in the matchmaking queue. The ticket ID is
+// the only credential needed to read or cancel it.
+type Ticket struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	AccountID  string       `json:"account_id,omitempty"`
+	Mode       game.Mode    `json:"mode"`
+	Skill      int          `json:"skill"`
+	SkillRange int          `json:"skill_range"`
+	Status     TicketStatus `json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	FinishedAt time.Time    `json:"finished_at,omitzero"`
+	Match      *Match       `json:"match,omitempty"`
+
+	done     chan struct{} // closed once the ticket leaves the queue
+	matching bool          // out of the queue while its match is set up
+}
+
+// window is how far apart in skill this ticket accepts opponents at now.
+func (t *Ticket) window(now time.Time) int {
+	waited := int(now.Sub(t.CreatedAt) / time.Second)
+	return min(t.SkillRange+waited*SkillWidenPerSecond, MaxSkillWindow)
+}
+
+// compatible reports whether two tickets accept each other at now.
+func compatible(a, b *Ticket, now time.Time) bool {
+	diff := abs(a.Skill - b.Skill)
+	return a.Mode == b.Mode && diff <= a.window(now) && diff <= b.window(now)
+}
+
+// ticketView is a ticket as reported to its holder, with its current skill
+// window.
+type ticketView struct {
+	Ticket
+	SkillWindow int `json:"skill_window,omitempty"`
+}
+
+// matchmaker holds the queue. A background matcher runs only while tickets
+// are queued.
+type matchmaker struct {
+	mu      sync.Mutex
+	tickets map[string]*Ticket
+	queue   []*Ticket // queued tickets, oldest first
+	running bool
+}
+
+func newMatchmaker() *matchmaker {
+	return &matchmaker{tickets: make(map[string]*Ticket)}
+}
+
+// finish takes a ticket out of the queue with a final status. The caller
+// must hold mm.mu.
+func (mm *matchmaker) finish(t *Ticket, status TicketStatus, now time.Time) {
+	t.Status = status
+	t.FinishedAt = now
+	close(t.done)
+
+	for i, queued := range mm.queue {
+		if queued == t {
+			mm.queue = append(mm.queue[:i], mm.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+// take removes a ticket from the queue while its match is set up. It stays
+// queued as far as its holder can tell, but cannot be cancelled. The caller
+// must hold mm.mu.
+func (mm *matchmaker) take(t *Ticket) {
+	t.matching = true
+	for i, queued := range mm.queue {
+		if queued == t {
+			mm.queue = append(mm.queue[:i], mm.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+// view snapshots a ticket. The caller must hold mm.mu.
+func (mm *matchmaker) view(t *Ticket, now time.Time) ticketView {
+	v := ticketView{Ticket: *t}
+	if t.Status == TicketQueued {
+		v.SkillWindow = t.window(now)
+	}
+	return v
+}
+
+// enqueue adds a ticket and starts the matcher if it is idle.
+func (s *Server) enqueue(t *Ticket) {
+	mm := s.matchmaker
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.tickets[t.ID] = t
+	mm.queue = append(mm.queue, t)
+	s.wakeMatcher()
+}
+
+// requeue puts back a ticket that could not be seated, in its original
+// place by age. The caller must hold mm.mu.
+func (s *Server) requeue(t *Ticket) {
+	mm := s.matchmaker
+	t.matching = false
+	i := sort.Search(len(mm.queue), func(i int) bool {
+		return mm.queue[i].CreatedAt.After(t.CreatedAt)
+	})
+	mm.queue = append(mm.queue[:i], append([]*Ticket{t}, mm.queue[i:]...)...)
+	s.wakeMatcher()
+}
+
+// wakeMatcher starts the matcher if it is idle. The caller must hold
+// mm.mu.
+func (s *Server) wakeMatcher() {
+	if !s.matchmaker.running {
+		s.matchmaker.running = true
+		go s.runMatcher()
+	}
+}
+
+// runMatcher forms matches every matchInterval until the queue is empty.
+func (s *Server) runMatcher() {
+	ticker := time.NewTicker(matchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !s.matchPass(time.Now()) {
+			return
+		}
+	}
+}
+
+// matchPass expires stale tickets, forms every match it can and purges old
+// finished tickets. Groups are taken out of the queue under mm.mu, but their
+// games are built and seated without it. It reports whether the matcher
+// should keep running.
+func (s *Server) matchPass(now time.Time) bool {
+	mm := s.matchmaker
+	mm.mu.Lock()
+
+	for _, t := range append([]*Ticket(nil), mm.queue...) {
+		if !now.Before(t.ExpiresAt) {
+			mm.finish(t, TicketExpired, now)
+		}
+	}
+
+	// The oldest ticket anchors each group, and the closest compatible
+	// skills join it first
+	var groups [][]*Ticket
+	for i := 0; i < len(mm.queue); {
+		anchor := mm.queue[i]
+		candidates := make([]*Ticket, 0, len(mm.queue))
+		for _, t := range mm.queue {
+			if t != anchor && compatible(anchor, t, now) {
+				candidates = append(candidates, t)
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return abs(candidates[a].Skill-anchor.Skill) < abs(candidates[b].Skill-anchor.Skill)
+		})
+
+		group := []*Ticket{anchor}
+		for _, c := range candidates {
+			if len(group) == MatchSize {
+				break
+			}
+			fits := true
+			for _, member := range group {
+				if !compatible(member, c, now) {
+					fits = false
+					break
+				}
+			}
+			if fits {
+				group = append(group, c)
+			}
+		}
+
+		full := len(group) == MatchSize
+		partial := len(group) >= MinMatchSize && now.Sub(anchor.CreatedAt) >= PartialMatchAfter
+		if !full && !partial {
+			i++
+			continue
+		}
+
+		// take shrinks the queue, so i now points at the next anchor
+		for _, t := range group {
+			mm.take(t)
+		}
+		groups = append(groups, group)
+	}
+
+	for id, t := range mm.tickets {
+		if t.Status != TicketQueued && now.Sub(t.FinishedAt) > ticketRetention {
+			delete(mm.tickets, id)
+		}
+	}
+
+	running := len(mm.queue) > 0
+	mm.running = running
+	mm.mu.Unlock()
+
+	// A ticket that cannot be seated goes back in the queue and restarts
+	// the matcher if this pass stopped it
+	for _, group := range groups {
+		s.startMatch(group, now)
+	}
+	return running
+}
+
+// startMatch creates a game for a group taken out of the queue and seats
+// every player in it. The game is removed once it sits idle. The caller must
+// not hold mm.mu.
+func (s *Server) startMatch(group []*Ticket, now time.Time) {
+	gameID := utils.GenerateID(8)
+	g := game.NewGame(gameID, game.Options{
+		Name:       "Match " + gameID,
+		Tags:       []string{"matchmaking"},
+		MaxPlayers: len(group),
+		Visibility: game.VisibilityUnlisted,
+		Mode:       group[0].Mode,
+		ChatFilter: s.chatFilter,
+		Quests:     s.quests,
+		OnUpdate:   func() { s.lobby.updated(gameID) },
+	})
+	s.addGame(g)
+	s.registerIdleCleanup(gameID)
+
+	matches := make([]*Match, len(group))
+	seated := 0
+	for i, t := range group {
+		match, err := s.seat(g, t)
+		if err != nil {
+			// Seating only fails on a broken game; the ticket goes back
+			// in the queue for the next pass rather than being stranded
+			log.Printf("Failed to seat matchmaking ticket %s: %v", t.ID, err)
+			continue
+		}
+		matches[i] = match
+		seated++
+	}
+	if seated == 0 {
+		s.removeGame(gameID)
+	}
+
+	mm := s.matchmaker
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	for i, t := range group {
+		if matches[i] == nil {
+			s.requeue(t)
+			continue
+		}
+		t.matching = false
+		t.Match = matches[i]
+		mm.finish(t, TicketMatched, now)
+	}
+}
+
+// seat adds a ticket's player to a matchmade game and issues their token.
+func (s *Server) seat(g *game.Game, t *Ticket) (*Match, error) {
+	player := newPlayer(g, t.Name)
+	if player == nil {
+		return nil, fmt.Errorf("no locations available")
+	}
+	player.AccountID = t.AccountID
+	if err := g.AddPlayer(player, "", ""); err != nil {
+		return nil, err
+	}
+	token, err := s.generateToken(g.ID, player.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Match{GameID: g.ID, Player: player, tokenPair: token}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// handleMatchmaking serves POST /matchmaking/tickets, which joins the queue.
+func (s *Server) handleMatchmaking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name       string `json:"name"`
+		Mode       string `json:"mode"`
+		Skill      *int   `json:"skill"`
+		SkillRange *int   `json:"skill_range"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Queueing with an account token makes the matched character the
+	// account's
+	account, status, err := s.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	errs := make(fieldErrors)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" && account != nil {
+		req.Name = account.DisplayName
+	}
+	if req.Name == "" {
+		errs.add("name", "is required")
+	}
+	mode, err := game.ParseMode(req.Mode)
+	if err != nil {
+		errs.add("mode", "must be realtime, turn_based or simultaneous")
+	}
+	skill, skillRange := DefaultSkill, DefaultSkillRange
+	if req.Skill != nil {
+		skill = *req.Skill
+		if skill < 0 {
+			errs.add("skill", "must not be negative")
+		}
+	}
+	if req.SkillRange != nil {
+		skillRange = *req.SkillRange
+		if skillRange < 0 || skillRange > MaxSkillWindow {
+			errs.add("skill_range", "must be between 0 and %d", MaxSkillWindow)
+		}
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	now := time.Now()
+	t := &Ticket{
+		ID:         utils.SecureID(16),
+		Name:       req.Name,
+		Mode:       mode,
+		Skill:      skill,
+		SkillRange: skillRange,
+		Status:     TicketQueued,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(QueueTimeout),
+		done:       make(chan struct{}),
+	}
+	if account != nil {
+		t.AccountID = account.ID
+	}
+	s.enqueue(t)
+
+	s.matchmaker.mu.Lock()
+	view := s.matchmaker.view(t, now)
+	s.matchmaker.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+// handleTicketRoutes serves /matchmaking/tickets/{id}: GET reads the
+// ticket, DELETE cancels it, and GET .../events streams its outcome.
+func (s *Server) handleTicketRoutes(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/matchmaking/tickets/"), "/")
+
+	mm := s.matchmaker
+	mm.mu.Lock()
+	t := mm.tickets[parts[0]]
+	mm.mu.Unlock()
+	if t == nil {
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "events" {
+		s.handleTicketSSE(w, r, t)
+		return
+	}
+	if len(parts) != 1 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		mm.mu.Lock()
+		if t.Status != TicketQueued {
+			mm.mu.Unlock()
+			http.Error(w, fmt.Sprintf("Ticket is already %s", t.Status), http.StatusConflict)
+			return
+		}
+		if t.matching {
+			mm.mu.Unlock()
+			http.Error(w, "Ticket is already being matched", http.StatusConflict)
+			return
+		}
+		mm.finish(t, TicketCancelled, time.Now())
+		mm.mu.Unlock()
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mm.mu.Lock()
+	view := mm.view(t, time.Now())
+	mm.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// handleTicketSSE sends the ticket's current state, then its final state
+// once it leaves the queue, and closes the stream.
+func (s *Server) handleTicketSSE(w http.ResponseWriter, r *http.Request, t *Ticket) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	writeTicket := func() {
+		s.matchmaker.mu.Lock()
+		view := s.matchmaker.view(t, time.Now())
+		s.matchmaker.mu.Unlock()
+
+		data, err := json.Marshal(view)
+		if err != nil {
+			return
+		}
+		w.Write([]byte("data: "))
+		w.Write(data)
+		w.Write([]byte("\n\n"))
+		flusher.Flush()
+	}
+
+	writeTicket()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			writeTicket()
+			return
+
+		case <-ticker.C:
+			w.Write([]byte(": keepalive\n\n"))
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"game-api/game"
+)
+
+// queueTicket queues a ticket created at createdAt. Tests mark the matcher
+// as running first, so they drive match passes themselves.
+func queueTicket(s *Server, name string, skill int, createdAt time.Time) *Ticket {
+	s.matchmaker.mu.Lock()
+	s.matchmaker.running = true
+	s.matchmaker.mu.Unlock()
+
+	t := &Ticket{
+		ID:         name,
+		Name:       name,
+		Mode:       game.ModeRealTime,
+		Skill:      skill,
+		SkillRange: DefaultSkillRange,
+		Status:     TicketQueued,
+		CreatedAt:  createdAt,
+		ExpiresAt:  createdAt.Add(QueueTimeout),
+		done:       make(chan struct{}),
+	}
+	s.enqueue(t)
+	return t
+}
+
+func TestMatchPass(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		skills  []int
+		age     time.Duration
+		matched int
+		status  TicketStatus // of the tickets left over
+	}{
+		{"full match", []int{1000, 1010, 990, 1005}, 0, 4, ""},
+		{"too few for a full match", []int{1000, 1010}, 0, 0, TicketQueued},
+		{"partial match after waiting", []int{1000, 1010}, PartialMatchAfter, 2, ""},
+		{"skills too far apart", []int{1000, 1500}, PartialMatchAfter, 0, TicketQueued},
+		{"fifth ticket waits", []int{1000, 1000, 1000, 1000, 1000}, 0, 4, TicketQueued},
+		{"expired tickets", []int{1000, 1010}, QueueTimeout, 0, TicketExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			var tickets []*Ticket
+			for i, skill := range tt.skills {
+				name := string(rune('a' + i))
+				tickets = append(tickets, queueTicket(s, name, skill, now.Add(-tt.age)))
+			}
+
+			s.matchPass(now)
+
+			matched := 0
+			for _, tk := range tickets {
+				switch tk.Status {
+				case TicketMatched:
+					matched++
+					if tk.Match == nil || s.getGame(tk.Match.GameID) == nil {
+						t.Errorf("ticket %s matched without a game", tk.ID)
+					}
+				case tt.status:
+				default:
+					t.Errorf("ticket %s status = %s, want %s", tk.ID, tk.Status, tt.status)
+				}
+				if tk.matching {
+					t.Errorf("ticket %s still marked as matching", tk.ID)
+				}
+			}
+			if matched != tt.matched {
+				t.Errorf("matched %d tickets, want %d", matched, tt.matched)
+			}
+		})
+	}
+}
+
+func TestRequeueKeepsAgeOrder(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	oldest := queueTicket(s, "a", 1000, now.Add(-3*time.Second))
+	newest := queueTicket(s, "c", 1000, now)
+
+	s.matchmaker.mu.Lock()
+	defer s.matchmaker.mu.Unlock()
+	middle := &Ticket{ID: "b", CreatedAt: now.Add(-time.Second), matching: true}
+	s.requeue(middle)
+
+	want := []*Ticket{oldest, middle, newest}
+	for i, tk := range s.matchmaker.queue {
+		if tk != want[i] {
+			t.Fatalf("queue[%d] = %s, want %s", i, tk.ID, want[i].ID)
+		}
+	}
+	if middle.matching {
+		t.Error("requeued ticket still marked as matching")
+	}
+}
+
+func TestTicketRoutes(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	queued := queueTicket(s, "queued", 1000, now)
+	matching := queueTicket(s, "matching", 1000, now)
+	s.matchmaker.mu.Lock()
+	s.matchmaker.take(matching)
+	s.matchmaker.mu.Unlock()
+
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		want   int
+	}{
+		{"unknown ticket", http.MethodGet, "nope", http.StatusNotFound},
+		{"read ticket", http.MethodGet, "queued", http.StatusOK},
+		{"cancel a ticket being matched", http.MethodDelete, "matching", http.StatusConflict},
+		{"cancel ticket", http.MethodDelete, "queued", http.StatusOK},
+		{"cancel twice", http.MethodDelete, "queued", http.StatusConflict},
+		{"bad method", http.MethodPost, "queued", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, tt.method, "/matchmaking/tickets/"+tt.id, "", nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+	if queued.Status != TicketCancelled {
+		t.Errorf("status = %s, want cancelled", queued.Status)
+	}
+}
+
+func TestHandleMatchmakingErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  map[string]interface{}
+		field string
+	}{
+		{"no name", map[string]interface{}{}, "name"},
+		{"blank name", map[string]interface{}{"name": "  "}, "name"},
+		{"unknown mode", map[string]interface{}{"name": "a", "mode": "async"}, "mode"},
+		{"negative skill", map[string]interface{}{"name": "a", "skill": -1}, "skill"},
+		{"skill range too wide", map[string]interface{}{"name": "a", "skill_range": MaxSkillWindow + 1}, "skill_range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			w := do(t, s, http.MethodPost, "/matchmaking/tickets", "", tt.body)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422", w.Code)
+			}
+			var resp struct {
+				Fields map[string]string `json:"fields"`
+			}
+			decode(t, w, &resp)
+			if _, ok := resp.Fields[tt.field]; !ok {
+				t.Errorf("fields = %v, want one for %s", resp.Fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestMatchmadeGamesAreCleanedUp(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	a := queueTicket(s, "a", 1000, now.Add(-PartialMatchAfter))
+	queueTicket(s, "b", 1000, now.Add(-PartialMatchAfter))
+	s.matchPass(now)
+	if a.Match == nil {
+		t.Fatal("tickets were not matched")
+	}
+	gameID := a.Match.GameID
+
+	if !s.sweepIdleGames(time.Now()) || s.getGame(gameID) == nil {
+		t.Fatal("fresh match was removed")
+	}
+
+	// Clients streaming the game keep it alive
+	g := s.getGame(gameID)
+	ch := make(chan game.Event, 1)
+	g.AddClient(ch, a.Match.Player.ID)
+	later := time.Now().Add(IdleGameTimeout)
+	s.sweepIdleGames(later)
+	if s.getGame(gameID) == nil {
+		t.Fatal("game with a connected client was removed")
+	}
+	g.RemoveClient(ch)
+
+	if s.sweepIdleGames(later.Add(IdleGameTimeout)) {
+		t.Error("sweeper kept running with no games registered")
+	}
+	if s.getGame(gameID) != nil {
+		t.Error("idle match was not removed")
+	}
+}
+
+func TestCreatedGamesAreNotCleanedUp(t *testing.T) {
+	s := newTestServer(t)
+	g := createTestGame(t, s, nil)
+	s.idle.touch(g.ID, time.Now())
+
+	s.sweepIdleGames(time.Now().Add(2 * IdleGameTimeout))
+	if s.getGame(g.ID) == nil {
+		t.Error("game created through POST /games was removed")
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"game-api/game"
+	"game-api/utils"
+)
+
+const (
+	maxNameLength     = 64
+	maxTagLength      = 32
+	maxTags           = 10
+	maxPlayersLimit   = 100
+	maxTeams          = 8
+	maxTeamNameLength = 32
+	maxPasswordLength = 128
+
+	// A tick window outside these bounds either resolves faster than
+	// clients can submit commands or leaves the game stalled.
+	minTickWindow = 50 * time.Millisecond
+	maxTickWindow = 10 * time.Second
+
+	// A turn may last at most this long before it passes to the next player
+	maxTurnTimeout = time.Hour
+)
+
+// GameConfig is the body of POST /games. Every field is optional.
+type GameConfig struct {
+	Name          string   `json:"name"`
+	Tags          []string `json:"tags"`
+	MaxPlayers    int      `json:"max_players"`
+	LocationCount int      `json:"location_count"`
+	Topology      string   `json:"topology"`
+	Ruleset       string   `json:"ruleset"`
+	Mode          string   `json:"mode"`
+	Visibility    string   `json:"visibility"`
+	Seed          int64    `json:"seed"`
+
+	// Password sets a join password. InviteOnly instead has the server
+	// generate an invite code, returned once in the creation response;
+	// private games always get one.
+	Password   string `json:"password"`
+	InviteOnly bool   `json:"invite_only"`
+
+	TurnTimeoutSeconds int      `json:"turn_timeout_seconds"`
+	TickWindowMs       int      `json:"tick_window_ms"`
+	Teams              []string `json:"teams"`
+	FriendlyFire       bool     `json:"friendly_fire"`
+	ScoreLimit         int      `json:"score_limit"`
+}
+
+// fieldErrors maps request fields to what is wrong with them.
+type fieldErrors map[string]string
+
+func (e fieldErrors) add(field, format string, args ...interface{}) {
+	if _, ok := e[field]; !ok {
+		e[field] = fmt.Sprintf(format, args...)
+	}
+}
+
+// writeFieldErrors reports validation failures as 422 with one message per
+// field.
+func writeFieldErrors(w http.ResponseWriter, errs fieldErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "Invalid game options",
+		"fields": errs,
+	})
+}
+
+// options validates the config and converts it to game options. All
+// problems are reported together rather than stopping at the first.
+func (c GameConfig) options() (game.Options, fieldErrors) {
+	errs := make(fieldErrors)
+	var opts game.Options
+
+	opts.Name = strings.TrimSpace(c.Name)
+	if len(opts.Name) > maxNameLength {
+		errs.add("name", "must be at most %d characters", maxNameLength)
+	}
+
+	if len(c.Tags) > maxTags {
+		errs.add("tags", "at most %d tags are allowed", maxTags)
+	}
+	for _, tag := range c.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxTagLength {
+			errs.add("tags", "each tag must be 1-%d characters", maxTagLength)
+			continue
+		}
+		opts.Tags = append(opts.Tags, tag)
+	}
+
+	if c.MaxPlayers < 0 || c.MaxPlayers > maxPlayersLimit {
+		errs.add("max_players", "must be between 0 (no limit) and %d", maxPlayersLimit)
+	}
+	opts.MaxPlayers = c.MaxPlayers
+
+	if c.LocationCount != 0 && (c.LocationCount < 2 || c.LocationCount > game.MaxLocationCount) {
+		errs.add("location_count", "must be between 2 and %d", game.MaxLocationCount)
+	}
+	opts.LocationCount = c.LocationCount
+
+	var err error
+	if opts.Topology, err = game.ParseTopology(c.Topology); err != nil {
+		errs.add("topology", "must be random, ring, grid or tree")
+	}
+	if opts.Ruleset, err = game.ParseRuleset(c.Ruleset); err != nil {
+		errs.add("ruleset", "must be standard, hardcore or casual")
+	}
+	if opts.Mode, err = game.ParseMode(c.Mode); err != nil {
+		errs.add("mode", "must be realtime, turn_based or simultaneous")
+	}
+
+	switch game.Visibility(c.Visibility) {
+	case "":
+	case game.VisibilityPublic, game.VisibilityUnlisted, game.VisibilityPrivate:
+		opts.Visibility = game.Visibility(c.Visibility)
+	default:
+		errs.add("visibility", "must be public, unlisted or private")
+	}
+
+	if len(c.Password) > maxPasswordLength {
+		errs.add("password", "must be at most %d characters", maxPasswordLength)
+	}
+	if c.Password != "" && c.InviteOnly {
+		errs.add("password", "cannot be combined with invite_only")
+	}
+	if c.Password != "" && opts.Visibility == game.VisibilityPrivate {
+		errs.add("password", "private games are joined by invite")
+	}
+	opts.Password = c.Password
+	// Someone has to get into a private game first, so it always starts
+	// with an invite
+	if c.InviteOnly || opts.Visibility == game.VisibilityPrivate {
+		opts.InviteCode = utils.SecureID(10)
+	}
+
+	// Bounds are checked before converting, so huge values cannot overflow
+	// into range
+	if c.TurnTimeoutSeconds < 0 || c.TurnTimeoutSeconds > int(maxTurnTimeout/time.Second) {
+		errs.add("turn_timeout_seconds", "must be between 0 and %d", int(maxTurnTimeout/time.Second))
+	}
+	opts.TurnTimeout = time.Duration(c.TurnTimeoutSeconds) * time.Second
+
+	// 0 leaves the default window
+	if c.TickWindowMs != 0 && (c.TickWindowMs < int(minTickWindow.Milliseconds()) || c.TickWindowMs > int(maxTickWindow.Milliseconds())) {
+		errs.add("tick_window_ms", "must be between %d and %d", minTickWindow.Milliseconds(), maxTickWindow.Milliseconds())
+	}
+	opts.TickWindow = time.Duration(c.TickWindowMs) * time.Millisecond
+
+	if c.ScoreLimit < 0 {
+		errs.add("score_limit", "must not be negative")
+	}
+	opts.ScoreLimit = c.ScoreLimit
+
+	if len(c.Teams) > maxTeams {
+		errs.add("teams", "at most %d teams are allowed", maxTeams)
+	}
+	seenTeams := make(map[string]bool)
+	for _, team := range c.Teams {
+		if team == "" || seenTeams[team] {
+			errs.add("teams", "must be unique, non-empty names")
+		}
+		if len(team) > maxTeamNameLength {
+			errs.add("teams", "each team name must be at most %d characters", maxTeamNameLength)
+		}
+		seenTeams[team] = true
+	}
+	opts.Teams = c.Teams
+	opts.FriendlyFire = c.FriendlyFire
+	opts.Seed = c.Seed
+
+	if len(errs) > 0 {
+		return opts, errs
+	}
+	return opts, nil
+}
+
+// optionsOf describes the options a game was created with. Join secrets are
+// never included.
+func optionsOf(g *game.Game) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                 g.Name,
+		"tags":                 g.Tags,
+		"max_players":          g.MaxPlayers,
+		"location_count":       len(g.Locations),
+		"topology":             g.Topology,
+		"ruleset":              g.Ruleset,
+		"mode":                 g.Mode,
+		"visibility":           g.Visibility,
+		"join_policy":          g.Join,
+		"seed":                 g.Seed,
+		"turn_timeout_seconds": int(g.TurnTimeout / time.Second),
+		"tick_window_ms":       g.TickWindow.Milliseconds(),
+		"teams":                g.Teams,
+		"friendly_fire":        g.FriendlyFire,
+		"score_limit":          g.ScoreLimit,
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameConfigOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   GameConfig
+		field string
+	}{
+		{"name too long", GameConfig{Name: strings.Repeat("n", maxNameLength+1)}, "name"},
+		{"too many tags", GameConfig{Tags: make([]string, maxTags+1)}, "tags"},
+		{"empty tag", GameConfig{Tags: []string{" "}}, "tags"},
+		{"negative max players", GameConfig{MaxPlayers: -1}, "max_players"},
+		{"too many max players", GameConfig{MaxPlayers: maxPlayersLimit + 1}, "max_players"},
+		{"one location", GameConfig{LocationCount: 1}, "location_count"},
+		{"unknown topology", GameConfig{Topology: "maze"}, "topology"},
+		{"unknown ruleset", GameConfig{Ruleset: "brutal"}, "ruleset"},
+		{"unknown mode", GameConfig{Mode: "async"}, "mode"},
+		{"unknown visibility", GameConfig{Visibility: "secret"}, "visibility"},
+		{"password too long", GameConfig{Password: strings.Repeat("p", maxPasswordLength+1)}, "password"},
+		{"password with invite_only", GameConfig{Password: "x", InviteOnly: true}, "password"},
+		{"password on private game", GameConfig{Password: "x", Visibility: "private"}, "password"},
+		{"negative turn timeout", GameConfig{TurnTimeoutSeconds: -1}, "turn_timeout_seconds"},
+		{"turn timeout too long", GameConfig{TurnTimeoutSeconds: 3601}, "turn_timeout_seconds"},
+		{"turn timeout overflowing", GameConfig{TurnTimeoutSeconds: math.MaxInt}, "turn_timeout_seconds"},
+		{"negative tick window", GameConfig{TickWindowMs: -1}, "tick_window_ms"},
+		{"tick window too short", GameConfig{TickWindowMs: 49}, "tick_window_ms"},
+		{"tick window too long", GameConfig{TickWindowMs: 10001}, "tick_window_ms"},
+		{"negative score limit", GameConfig{ScoreLimit: -1}, "score_limit"},
+		{"too many teams", GameConfig{Teams: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}}, "teams"},
+		{"team name too long", GameConfig{Teams: []string{"red", strings.Repeat("t", maxTeamNameLength+1)}}, "teams"},
+		{"duplicate team", GameConfig{Teams: []string{"red", "red"}}, "teams"},
+		{"empty team", GameConfig{Teams: []string{"red", ""}}, "teams"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := tt.cfg.options()
+			if _, ok := errs[tt.field]; !ok {
+				t.Errorf("errors = %v, want one for %s", errs, tt.field)
+			}
+		})
+	}
+}
+
+func TestGameConfigOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  GameConfig
+	}{
+		{"empty config", GameConfig{}},
+		{"shortest tick window", GameConfig{TickWindowMs: 50}},
+		{"longest tick window", GameConfig{TickWindowMs: 10000}},
+		{"longest turn timeout", GameConfig{TurnTimeoutSeconds: 3600}},
+		{"full teams", GameConfig{Teams: []string{"a", "b", "c", "d", "e", "f", "g", "h"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, errs := tt.cfg.options()
+			if errs != nil {
+				t.Fatalf("errors = %v", errs)
+			}
+			if want := time.Duration(tt.cfg.TickWindowMs) * time.Millisecond; opts.TickWindow != want {
+				t.Errorf("TickWindow = %v, want %v", opts.TickWindow, want)
+			}
+		})
+	}
+}
+
+func TestGameConfigInviteCode(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  GameConfig
+		want bool
+	}{
+		{"public game", GameConfig{}, false},
+		{"invite only", GameConfig{InviteOnly: true}, true},
+		{"private game", GameConfig{Visibility: "private"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, errs := tt.cfg.options()
+			if errs != nil {
+				t.Fatalf("errors = %v", errs)
+			}
+			if got := opts.InviteCode != ""; got != tt.want {
+				t.Errorf("has invite code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateGameReportsFieldErrors(t *testing.T) {
+	s := newTestServer(t)
+	w := do(t, s, http.MethodPost, "/games", "", map[string]interface{}{"tick_window_ms": 5, "mode": "async"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", w.Code)
+	}
+	var resp struct {
+		Fields map[string]string `json:"fields"`
+	}
+	decode(t, w, &resp)
+	if len(resp.Fields) != 2 {
+		t.Errorf("fields = %v, want tick_window_ms and mode", resp.Fields)
+	}
+}
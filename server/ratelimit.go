@@ -0,0 +1,80 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often allow looks for idle buckets to drop.
+const sweepInterval = time.Minute
+
+// rateLimiter is a per-key token bucket. It caps how many requests a single
+// player can make regardless of which action they ask for.
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token for key. When the bucket is empty it returns false and
+// how long until the next token is available.
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	return rl.allowAt(key, time.Now())
+}
+
+func (rl *rateLimiter) allowAt(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.sweep(now)
+
+	b := rl.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that have refilled completely. A full bucket behaves
+// exactly like a missing one, so keys that stop making requests cost nothing.
+// The caller must hold rl.mu.
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < sweepInterval {
+		return
+	}
+	rl.lastSweep = now
+
+	for key, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name     string
+		requests []time.Duration // offsets from start
+		want     []bool
+	}{
+		{"within burst", []time.Duration{0, 0, 0}, []bool{true, true, true}},
+		{"burst exhausted", []time.Duration{0, 0, 0, 0}, []bool{true, true, true, false}},
+		{"refills over time", []time.Duration{0, 0, 0, 0, time.Second}, []bool{true, true, true, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := newRateLimiter(1, 3)
+			for i, offset := range tt.requests {
+				ok, wait := rl.allowAt("k", start.Add(offset))
+				if ok != tt.want[i] {
+					t.Fatalf("request %d: allowed = %v, want %v", i, ok, tt.want[i])
+				}
+				if !ok && wait <= 0 {
+					t.Errorf("request %d: rejected without a retry delay", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	rl := newRateLimiter(1, 3)
+	start := time.Now()
+
+	rl.allowAt("idle", start)
+	// The sweep runs before this key drains its bucket again
+	for range 3 {
+		rl.allowAt("busy", start.Add(sweepInterval))
+	}
+
+	if _, ok := rl.buckets["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := rl.buckets["busy"]; !ok {
+		t.Error("busy bucket was swept")
+	}
+	if ok, _ := rl.allowAt("busy", start.Add(sweepInterval)); ok {
+		t.Error("sweeping reset a drained bucket")
+	}
+}
@@ -1,9 +1,12 @@
 package server
 
 import (
+	"log"
 	"net/http"
+	"os"
 	"sync"
 
+	"game-api/accounts"
 	"game-api/config"
 	"game-api/game"
 )
@@ -11,9 +14,22 @@ import (
 type Server struct {
 	games   map[string]*game.Game
 	gamesMu sync.RWMutex
+	lobby   *lobby
+
+	matchmaker *matchmaker
+	idle       *idleTracker
+	accounts   accounts.Store
+	tokens     *tokenStore
+	keys       *keyring
 
 	router *http.ServeMux
 	config *config.Config
+
+	actionLimiter *rateLimiter
+	loginLimiter  *rateLimiter
+	signupLimiter *rateLimiter
+	chatFilter    *game.ChatFilter
+	quests        []*game.Quest
 }
 
 func NewServer(cfg *config.Config) *Server {
@@ -21,21 +37,113 @@ func NewServer(cfg *config.Config) *Server {
 		games:  make(map[string]*game.Game),
 		router: http.NewServeMux(),
 		config: cfg,
+
+		matchmaker: newMatchmaker(),
+		idle:       newIdleTracker(),
+		tokens:     newTokenStore(),
+
+		actionLimiter: newRateLimiter(10, 20),
+		loginLimiter:  newRateLimiter(0.2, 5),
+		signupLimiter: newRateLimiter(1.0/60, 3),
+	}
+	s.lobby = newLobby(s.getGame)
+
+	if cfg.ChatFilterFile != "" {
+		s.chatFilter = loadChatFilter(cfg.ChatFilterFile)
+	}
+
+	if cfg.QuestsFile != "" {
+		s.quests = loadQuests(cfg.QuestsFile)
+	}
+
+	keys, err := newKeyring(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up JWT keys: %v", err)
+	}
+	s.keys = keys
+	if cfg.JWTKeyRotation > 0 {
+		go keys.rotateEvery(cfg.JWTKeyRotation)
+	}
+
+	if cfg.AccountsFile != "" {
+		s.accounts = loadAccounts(cfg.AccountsFile)
+	} else {
+		s.accounts = accounts.NewMemoryStore()
 	}
 
 	s.registerRoutes()
 	return s
 }
 
+func loadChatFilter(path string) *game.ChatFilter {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open chat filter: %v", err)
+	}
+	defer f.Close()
+
+	filter, err := game.LoadChatFilter(f)
+	if err != nil {
+		log.Fatalf("Failed to load chat filter: %v", err)
+	}
+	log.Printf("Loaded chat filter from %s", path)
+	return filter
+}
+
+func loadQuests(path string) []*game.Quest {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open quests file: %v", err)
+	}
+	defer f.Close()
+
+	quests, err := game.LoadQuests(f)
+	if err != nil {
+		log.Fatalf("Failed to load quests: %v", err)
+	}
+	log.Printf("Loaded %d quests from %s", len(quests), path)
+	// Location names are generated per world, so a quest naming one is
+	// only offered in worlds that happen to have it
+	for _, q := range quests {
+		for _, o := range q.Objectives {
+			if o.Location != "" {
+				log.Printf("WARNING: Quest %q names location %q and will be left out of worlds without it; use a biome to place it in any world", q.ID, o.Location)
+				break
+			}
+		}
+	}
+	return quests
+}
+
+func loadAccounts(path string) accounts.Store {
+	store, err := accounts.NewFileStore(path)
+	if err != nil {
+		log.Fatalf("Failed to load accounts: %v", err)
+	}
+	log.Printf("Loaded %d accounts from %s", store.Len(), path)
+	return store
+}
+
 func (s *Server) registerRoutes() {
 	s.router.HandleFunc("/games", s.corsMiddleware(s.handleCreateGame))
 	s.router.HandleFunc("/games/", s.corsMiddleware(s.handleGameRoutes))
+	s.router.HandleFunc("/matchmaking/tickets", s.corsMiddleware(s.handleMatchmaking))
+	s.router.HandleFunc("/matchmaking/tickets/", s.corsMiddleware(s.handleTicketRoutes))
+	s.router.HandleFunc("/accounts", s.corsMiddleware(s.handleRegister))
+	s.router.HandleFunc("/accounts/login", s.corsMiddleware(s.handleLogin))
+	s.router.HandleFunc("/accounts/me", s.corsMiddleware(s.requireAccount(s.handleAccount)))
+	s.router.HandleFunc("/admin/login", s.corsMiddleware(s.handleAdminLogin))
+	s.router.HandleFunc("/admin/games", s.corsMiddleware(s.requireRole(adminRoles, s.handleAdminGames)))
+	s.router.HandleFunc("/admin/games/", s.corsMiddleware(s.requireRole(adminRoles, s.handleAdminGameRoutes)))
+	s.router.HandleFunc("/auth/refresh", s.corsMiddleware(s.handleRefresh))
+	s.router.HandleFunc("/auth/logout", s.corsMiddleware(s.handleLogout))
+	s.router.HandleFunc("/.well-known/jwks.json", s.corsMiddleware(s.handleJWKS))
 }
 
 func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", s.config.AllowedOrigins)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
@@ -49,8 +157,10 @@ func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 
 func (s *Server) addGame(g *game.Game) {
 	s.gamesMu.Lock()
-	defer s.gamesMu.Unlock()
 	s.games[g.ID] = g
+	s.gamesMu.Unlock()
+
+	s.lobby.created(g)
 }
 
 func (s *Server) getGame(id string) *game.Game {
@@ -61,8 +171,16 @@ func (s *Server) getGame(id string) *game.Game {
 
 func (s *Server) removeGame(id string) {
 	s.gamesMu.Lock()
-	defer s.gamesMu.Unlock()
+	g := s.games[id]
 	delete(s.games, id)
+	s.gamesMu.Unlock()
+	s.idle.forget(id)
+
+	if g != nil {
+		g.Stop()
+		g.DisconnectAll()
+		s.lobby.removed(g)
+	}
 }
 
 func (s *Server) Start(addr string) error {
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"game-api/config"
+	"game-api/game"
+)
+
+// newTestServer creates a server signing HS256 tokens with a fixed secret.
+// Every test request comes from the same address, so registrations are not
+// rate limited. Every game it creates is stopped when the test ends.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer(&config.Config{
+		JWTSecret:       []byte("test-secret"),
+		AllowedOrigins:  "*",
+		AdminCredential: "test-admin",
+	})
+	s.signupLimiter = newRateLimiter(1000, 1000)
+	t.Cleanup(func() {
+		s.gamesMu.RLock()
+		defer s.gamesMu.RUnlock()
+		for _, g := range s.games {
+			g.Stop()
+		}
+	})
+	return s
+}
+
+// do sends a request to s, with body encoded as JSON unless it is nil and
+// token sent as a bearer token unless it is empty.
+func do(t *testing.T, s *Server, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encoding body: %v", err)
+		}
+	}
+	r := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// decode unmarshals a response body, failing the test if it is not JSON.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+}
+
+// createTestGame creates a game with cfg and returns it.
+func createTestGame(t *testing.T, s *Server, cfg map[string]interface{}) *game.Game {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/games", "", cfg)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating game: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		GameID string `json:"game_id"`
+	}
+	decode(t, w, &resp)
+	return s.getGame(resp.GameID)
+}
+
+// joinTestGame joins a player named name and returns its ID and token.
+func joinTestGame(t *testing.T, s *Server, g *game.Game, name string) (string, string) {
+	t.Helper()
+	w := do(t, s, http.MethodPost, "/games/"+g.ID+"/players", "", map[string]string{"name": name})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("joining %s: %d %s", name, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Player struct {
+			ID string `json:"id"`
+		} `json:"player"`
+		Token string `json:"token"`
+	}
+	decode(t, w, &resp)
+	return resp.Player.ID, resp.Token
+}
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"game-api/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessTokenTTL is how long a JWT is accepted. Clients renew it with
+	// their refresh token rather than holding long-lived credentials.
+	AccessTokenTTL = 15 * time.Minute
+
+	// RefreshTokenTTL is how long a refresh token stays usable. Every
+	// refresh rotates it and restarts the clock.
+	RefreshTokenTTL = 7 * 24 * time.Hour
+
+	// InviteLinkTTL bounds how long a signed invite link works, so no
+	// signing key has to be kept for ever. Listing invites signs fresh links.
+	InviteLinkTTL = 7 * 24 * time.Hour
+
+	pruneInterval = time.Minute
+	maxRetired    = 16 // rotated-out refresh tokens remembered for reuse detection
+)
+
+var (
+	errRefreshInvalid = fmt.Errorf("invalid or expired refresh token")
+	errRefreshReused  = fmt.Errorf("refresh token was already used; the session has been revoked")
+)
+
+// tokenPair is what the server hands out wherever it issues credentials.
+// The access token keeps the "token" field name older clients read.
+type tokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // seconds until the access token expires
+}
+
+// session is one login: the access tokens issued to it and its current
+// refresh token. Refresh tokens are stored only as hashes.
+type session struct {
+	id        string
+	subject   string
+	claims    Claims               // copied into every access token
+	refresh   string               // hash of the only refresh token that still works
+	retired   []string             // hashes of rotated-out refresh tokens
+	expiresAt time.Time            // when the current refresh token expires
+	access    map[string]time.Time // jti -> expiry of every access token issued
+}
+
+// tokenStore tracks sessions and the revocation list. A revoked jti is kept
+// only until the token would have expired anyway.
+type tokenStore struct {
+	mu        sync.Mutex
+	sessions  map[string]*session
+	refresh   map[string]*session        // refresh hash -> session, current and retired
+	subjects  map[string]map[string]bool // subject -> session IDs
+	revoked   map[string]time.Time       // jti -> token expiry
+	lastPrune time.Time
+}
+
+func newTokenStore() *tokenStore {
+	return &tokenStore{
+		sessions: make(map[string]*session),
+		refresh:  make(map[string]*session),
+		subjects: make(map[string]map[string]bool),
+		revoked:  make(map[string]time.Time),
+	}
+}
+
+// subjectOf names whose credentials the claims are, so everything issued to
+// one player, spectator or account can be revoked together.
+func subjectOf(c *Claims) string {
+	switch {
+	case c.hasRole(RoleAdmin):
+		return "admin:" + c.Subject
+	case c.hasRole(RoleSpectator):
+		return playerSubject(c.GameID, "spectator:"+c.Subject)
+	case c.GameID != "":
+		return playerSubject(c.GameID, c.PlayerID)
+	default:
+		return "account:" + c.AccountID
+	}
+}
+
+func playerSubject(gameID, playerID string) string {
+	return "game:" + gameID + ":" + playerID
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// isRevoked reports whether the token with this jti has been revoked.
+func (ts *tokenStore) isRevoked(jti string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	_, ok := ts.revoked[jti]
+	return ok
+}
+
+// revokeSession ends a session: its access tokens are revoked and none of
+// its refresh tokens work again. The caller must hold ts.mu.
+func (ts *tokenStore) revokeSession(sess *session) {
+	for jti, exp := range sess.access {
+		ts.revoked[jti] = exp
+	}
+	ts.forget(sess)
+}
+
+// forget drops a session without revoking anything. The caller must hold
+// ts.mu.
+func (ts *tokenStore) forget(sess *session) {
+	delete(ts.refresh, sess.refresh)
+	for _, hash := range sess.retired {
+		delete(ts.refresh, hash)
+	}
+	delete(ts.sessions, sess.id)
+	if ids := ts.subjects[sess.subject]; ids != nil {
+		delete(ids, sess.id)
+		if len(ids) == 0 {
+			delete(ts.subjects, sess.subject)
+		}
+	}
+}
+
+// revokeSubject ends every session belonging to subject.
+func (ts *tokenStore) revokeSubject(subject string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for id := range ts.subjects[subject] {
+		ts.revokeSession(ts.sessions[id])
+	}
+}
+
+// prune drops expired sessions and revocations. The caller must hold ts.mu.
+func (ts *tokenStore) prune(now time.Time) {
+	if now.Sub(ts.lastPrune) < pruneInterval {
+		return
+	}
+	ts.lastPrune = now
+
+	for jti, exp := range ts.revoked {
+		if now.After(exp) {
+			delete(ts.revoked, jti)
+		}
+	}
+	for _, sess := range ts.sessions {
+		for jti, exp := range sess.access {
+			if now.After(exp) {
+				delete(sess.access, jti)
+			}
+		}
+		// Once the refresh token is gone, the session can only hold
+		// access tokens that have not expired yet
+		if now.After(sess.expiresAt) && len(sess.access) == 0 {
+			ts.forget(sess)
+		}
+	}
+}
+
+// issueTokens starts a new session for claims and returns its first tokens.
+func (s *Server) issueTokens(claims Claims) (tokenPair, error) {
+	ts := s.tokens
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	ts.prune(now)
+
+	sess := &session{
+		id:      utils.SecureID(16),
+		subject: subjectOf(&claims),
+		claims:  claims,
+		access:  make(map[string]time.Time),
+	}
+	pair, err := s.rotate(sess, now)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	ts.sessions[sess.id] = sess
+	if ts.subjects[sess.subject] == nil {
+		ts.subjects[sess.subject] = make(map[string]bool)
+	}
+	ts.subjects[sess.subject][sess.id] = true
+	return pair, nil
+}
+
+// rotate signs a new access token and replaces the session's refresh token.
+// The caller must hold s.tokens.mu.
+func (s *Server) rotate(sess *session, now time.Time) (tokenPair, error) {
+	claims := sess.claims
+	claims.SessionID = sess.id
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        utils.SecureID(16),
+		Subject:   sess.claims.Subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+	}
+
+	access, err := s.keys.sign(claims)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return tokenPair{}, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(raw)
+
+	ts := s.tokens
+	if sess.refresh != "" {
+		sess.retired = append(sess.retired, sess.refresh)
+		if len(sess.retired) > maxRetired {
+			delete(ts.refresh, sess.retired[0])
+			sess.retired = sess.retired[1:]
+		}
+	}
+	sess.refresh = hashToken(refresh)
+	sess.expiresAt = now.Add(RefreshTokenTTL)
+	sess.access[claims.ID] = claims.ExpiresAt.Time
+	ts.refresh[sess.refresh] = sess
+
+	return tokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// refreshTokens exchanges a refresh token for a new pair. Presenting a
+// refresh token that was already rotated out means it leaked, so the whole
+// session is revoked.
+func (s *Server) refreshTokens(refresh string) (tokenPair, error) {
+	ts := s.tokens
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	ts.prune(now)
+
+	hash := hashToken(refresh)
+	sess := ts.refresh[hash]
+	if sess == nil {
+		return tokenPair{}, errRefreshInvalid
+	}
+	if hash != sess.refresh {
+		ts.revokeSession(sess)
+		return tokenPair{}, errRefreshReused
+	}
+	if !now.Before(sess.expiresAt) {
+		ts.forget(sess)
+		return tokenPair{}, errRefreshInvalid
+	}
+	return s.rotate(sess, now)
+}
+
+// logout ends the session the claims belong to, or with all set, every
+// session of the same subject.
+func (s *Server) logout(claims *Claims, all bool) {
+	if all {
+		s.tokens.revokeSubject(subjectOf(claims))
+		return
+	}
+
+	ts := s.tokens
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if sess := ts.sessions[claims.SessionID]; sess != nil {
+		ts.revokeSession(sess)
+	}
+	// The presented token goes on the list even if its session is gone
+	ts.revoked[claims.ID] = claims.ExpiresAt.Time
+}
+
+// handleRefresh serves POST /auth/refresh.
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	pair, err := s.refreshTokens(req.RefreshToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pair)
+}
+
+// handleLogout serves POST /auth/logout. It revokes the presented access
+// token's session; {"all": true} signs out every session of the same
+// player, spectator or account.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, status, err := s.bearerClaims(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// The body is optional
+	var req struct {
+		All bool `json:"all"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.logout(claims, req.All)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokens(t *testing.T) {
+	s := newTestServer(t)
+	first, err := s.generateToken("g", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.validateToken(first.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.SessionID) != 16 || len(claims.ID) != 16 {
+		t.Errorf("session ID %q and jti %q, want 16 characters each", claims.SessionID, claims.ID)
+	}
+
+	second, err := s.refreshTokens(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("refresh did not rotate the tokens")
+	}
+	if _, err := s.refreshTokens("garbage"); err != errRefreshInvalid {
+		t.Errorf("unknown refresh token: err = %v, want %v", err, errRefreshInvalid)
+	}
+
+	// Reusing a rotated-out refresh token revokes the whole session
+	if _, err := s.refreshTokens(first.RefreshToken); err != errRefreshReused {
+		t.Fatalf("reused refresh token: err = %v, want %v", err, errRefreshReused)
+	}
+	for _, token := range []string{first.AccessToken, second.AccessToken} {
+		if _, err := s.validateToken(token); err == nil {
+			t.Error("access token still valid after refresh token reuse")
+		}
+	}
+	if _, err := s.refreshTokens(second.RefreshToken); err == nil {
+		t.Error("revoked session still refreshes")
+	}
+}
+
+func TestRefreshTokenExpiry(t *testing.T) {
+	s := newTestServer(t)
+	pair, _ := s.generateToken("g", "p")
+	s.tokens.mu.Lock()
+	s.tokens.refresh[hashToken(pair.RefreshToken)].expiresAt = time.Now().Add(-time.Second)
+	s.tokens.mu.Unlock()
+
+	if _, err := s.refreshTokens(pair.RefreshToken); err != errRefreshInvalid {
+		t.Errorf("err = %v, want %v", err, errRefreshInvalid)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	for _, all := range []bool{false, true} {
+		s := newTestServer(t)
+		this, _ := s.generateToken("g", "p")
+		other, _ := s.generateToken("g", "p")
+		claims, err := s.validateToken(this.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s.logout(claims, all)
+
+		if _, err := s.validateToken(this.AccessToken); err == nil {
+			t.Errorf("all=%v: logged out access token still valid", all)
+		}
+		if _, err := s.refreshTokens(this.RefreshToken); err == nil {
+			t.Errorf("all=%v: logged out refresh token still works", all)
+		}
+		if _, err := s.validateToken(other.AccessToken); (err == nil) == all {
+			t.Errorf("all=%v: other session error = %v", all, err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s := newTestServer(t)
+	pair, _ := s.generateToken("g", "p")
+	claims, _ := s.validateToken(pair.AccessToken)
+	s.logout(claims, false)
+	live, _ := s.generateToken("g", "q")
+
+	ts := s.tokens
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.prune(time.Now().Add(AccessTokenTTL + time.Minute))
+	if len(ts.revoked) != 0 {
+		t.Errorf("%d revocations kept after their tokens expired", len(ts.revoked))
+	}
+	if len(ts.sessions) != 1 || ts.refresh[hashToken(live.RefreshToken)] == nil {
+		t.Error("prune dropped a session whose refresh token is still valid")
+	}
+
+	ts.lastPrune = time.Time{}
+	ts.prune(time.Now().Add(RefreshTokenTTL + time.Minute))
+	if len(ts.sessions) != 0 || len(ts.refresh) != 0 || len(ts.subjects) != 0 {
+		t.Error("prune kept an expired session")
+	}
+}
+
+func TestTokenRoutes(t *testing.T) {
+	s := newTestServer(t)
+	pair, _ := s.generateToken("g", "p")
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		body  interface{}
+		want  int
+	}{
+		{"refresh without token", "/auth/refresh", "", map[string]string{}, http.StatusBadRequest},
+		{"refresh", "/auth/refresh", "", map[string]string{"refresh_token": pair.RefreshToken}, http.StatusOK},
+		{"logout without token", "/auth/logout", "", nil, http.StatusUnauthorized},
+		{"logout", "/auth/logout", pair.AccessToken, nil, http.StatusNoContent},
+		{"logout again", "/auth/logout", pair.AccessToken, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, s, http.MethodPost, tt.path, tt.token, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
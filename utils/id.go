@@ -1,6 +1,7 @@
 package utils
 
 import (
+	crand "crypto/rand"
 	"math/rand"
 	"time"
 )
@@ -9,9 +10,41 @@ const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
 func GenerateID(length int) string {
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return GenerateIDFrom(rng, length)
+}
+
+// GenerateIDFrom draws an ID from rng, so a seeded rng gives repeatable IDs.
+func GenerateIDFrom(rng *rand.Rand, length int) string {
 	b := make([]byte, length)
 	for i := range b {
 		b[i] = charset[rng.Intn(len(charset))]
 	}
 	return string(b)
 }
+
+// SecureID draws an ID from crypto/rand. Use it for anything that grants
+// access when presented, such as invite codes, tickets and session IDs;
+// GenerateID is predictable.
+func SecureID(length int) string {
+	// 248 is the largest multiple of len(charset) below 256, so rejecting
+	// bytes at or above it keeps every character equally likely
+	const limit = 256 - 256%len(charset)
+
+	b := make([]byte, length)
+	buf := make([]byte, length)
+	for i := 0; i < length; {
+		// crypto/rand.Read never returns an error
+		crand.Read(buf)
+		for _, c := range buf {
+			if int(c) >= limit {
+				continue
+			}
+			b[i] = charset[int(c)%len(charset)]
+			i++
+			if i == length {
+				break
+			}
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSecureID(t *testing.T) {
+	for _, length := range []int{0, 1, 10, 100} {
+		id := SecureID(length)
+		if len(id) != length {
+			t.Errorf("SecureID(%d) has length %d", length, len(id))
+		}
+		for _, c := range id {
+			if !strings.ContainsRune(charset, c) {
+				t.Errorf("SecureID(%d) = %q contains %q", length, id, c)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := SecureID(16)
+		if seen[id] {
+			t.Fatalf("SecureID repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestGenerateIDFrom(t *testing.T) {
+	a := GenerateIDFrom(rand.New(rand.NewSource(1)), 12)
+	b := GenerateIDFrom(rand.New(rand.NewSource(1)), 12)
+	if a != b {
+		t.Errorf("same seed gave %q and %q", a, b)
+	}
+	if len(a) != 12 {
+		t.Errorf("length = %d, want 12", len(a))
+	}
+}